@@ -61,7 +61,7 @@ func (b *Book) lookup(position *Position) (entries []Entry) {
 
 	// Since book entries are ordered by polyglot key we can use binary
 	// search to find *first* book entry that matches the position.
-	first, current, last := int64(-1), int64(0), b.entries
+	first, current, last := int64(0), int64(0), b.entries
 	for first < last {
 		current = (first + last) / 2
 		file.Seek(current*16, 0)
@@ -76,8 +76,7 @@ func (b *Book) lookup(position *Position) (entries []Entry) {
 	// Read all book entries for the given position.
 	file.Seek(first*16, 0)
 	for {
-		binary.Read(file, binary.BigEndian, &entry)
-		if key != entry.Key {
+		if err := binary.Read(file, binary.BigEndian, &entry); err != nil || key != entry.Key {
 			break
 		} else {
 			entries = append(entries, entry)
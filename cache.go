@@ -0,0 +1,170 @@
+package kingside
+
+import (
+	`unsafe`
+)
+
+// Cache entry flags that tell how the cached score relates to the search window.
+const (
+	cacheNone  = iota
+	cacheAlpha // Upper bound: the score failed low.
+	cacheBeta  // Lower bound: the score failed high.
+	cacheExact // Exact score within alpha/beta window.
+)
+
+type CacheEntry struct {
+	id       uint32  // Upper 32 bits of the position's hash.
+	move     Move    // Best move found in the position.
+	score    int16   // Score adjusted for mate distance from the position.
+	depth    int16   // Depth the position was searched to.
+	flags    uint8   // Score bound type.
+	token    uint8   // Search token the entry was created by.
+}
+
+// Transposition table is split into buckets of four entries each. The position
+// could be stored in any entry of its bucket.
+const cacheBucket = 4
+
+type Cache struct {
+	entries  []CacheEntry
+	mask     uint64  // Bitmask to get bucket index from the hash.
+	token    uint8   // Current search token used to tell stale entries.
+}
+
+// Use single statically allocated variable.
+var cache Cache
+
+// Allocates transposition table of given size in megabytes. The number of
+// entries is rounded down to the power of two.
+func NewCache(megaBytes int) *Cache {
+	cache = Cache{}
+	if megaBytes > 0 {
+		size := uint64(megaBytes) * 1024 * 1024 / uint64(unsafe.Sizeof(CacheEntry{}))
+		for size & (size - 1) != 0 {
+			size &= size - 1
+		}
+		if size < cacheBucket {
+			size = cacheBucket
+		}
+		cache.entries = make([]CacheEntry, size)
+		cache.mask = (size - 1) & ^uint64(cacheBucket - 1)
+	}
+	return &cache
+}
+
+// Clears all entries, for example when starting new game.
+func (c *Cache) clear() *Cache {
+	for i := range c.entries {
+		c.entries[i] = CacheEntry{}
+	}
+	c.token = 0
+	return c
+}
+
+// Starts new search making existing entries stale, i.e. preferred candidates
+// for replacement.
+func (c *Cache) newSearch() *Cache {
+	c.token++
+	return c
+}
+
+// Returns approximate table usage in permille as expected by UCI "hashfull".
+func (c *Cache) usage() int {
+	sample := min(1000, len(c.entries))
+	if sample == 0 {
+		return 0
+	}
+
+	used := 0
+	for i := 0; i < sample; i++ {
+		if c.entries[i].id != 0 && c.entries[i].token == c.token {
+			used++
+		}
+	}
+	return used * 1000 / sample
+}
+
+// Stores the position in the table. Within the bucket we either reuse the entry
+// for the same position or replace the one from the older search with the lowest
+// depth.
+func (p *Position) cache(move Move, score, depth, ply int, flags uint8) *Position {
+	if len(cache.entries) == 0 {
+		return p
+	}
+
+	id := uint32(p.hash >> 32)
+	index := p.hash & cache.mask
+	bucket := cache.entries[index : index + cacheBucket]
+
+	victim := &bucket[0]
+	for i := range bucket {
+		entry := &bucket[i]
+		if entry.id == id {
+			// Keep the move from the previous search if we don't have one.
+			if move == Move(0) {
+				move = entry.move
+			}
+			victim = entry
+			break
+		}
+		if replacement(entry) < replacement(victim) {
+			victim = entry
+		}
+	}
+
+	// Mate scores get stored relative to the position rather than the root.
+	if score > Checkmate - MaxPly && score <= Checkmate {
+		score += ply
+	} else if score >= -Checkmate && score < -Checkmate + MaxPly {
+		score -= ply
+	}
+
+	*victim = CacheEntry{
+		id:    id,
+		move:  move,
+		score: int16(score),
+		depth: int16(depth),
+		flags: flags,
+		token: cache.token,
+	}
+
+	return p
+}
+
+// Returns cached entry for the position or nil if the position is not there.
+func (p *Position) probeCache() *CacheEntry {
+	if len(cache.entries) == 0 {
+		return nil
+	}
+
+	id := uint32(p.hash >> 32)
+	index := p.hash & cache.mask
+	for i := index; i < index + cacheBucket; i++ {
+		if entry := &cache.entries[i]; entry.id == id {
+			return entry
+		}
+	}
+	return nil
+}
+
+// Returns best move for the position as it was found by previous search, or
+// Move(0) if the position is not cached.
+func (p *Position) cachedMove() Move {
+	if entry := p.probeCache(); entry != nil {
+		return entry.move
+	}
+	return Move(0)
+}
+
+// Replacement priority of the cache entry: empty entries go first, then the
+// entries from older searches, and finally the ones with lower depth.
+func replacement(entry *CacheEntry) int {
+	if entry.id == 0 {
+		return -1
+	}
+	priority := int(entry.depth)
+	if entry.token == cache.token {
+		priority += MaxDepth * 2
+	}
+	return priority
+}
@@ -0,0 +1,225 @@
+package kingside
+
+import (
+	`bufio`
+	`fmt`
+	`io`
+	`os/exec`
+	`strconv`
+	`strings`
+	`time`
+)
+
+// Time in milliseconds we give external engine to respond on top of the
+// expected time, ex. to start up or to react to "stop".
+const clientGrace = 1000
+
+// Search information reported by external engine in its last "info" line.
+type ClientInfo struct {
+	depth    int       // Search depth.
+	score    int       // Score from the side to move, mates as +/-(Checkmate - ply).
+	nodes    int64     // Number of nodes searched.
+	pv       []string  // Principal variation in coordinate notation.
+}
+
+// UCI client that drives external engine running as a subprocess. This is the
+// other side of Engine.Uci().
+type Client struct {
+	name      string          // Engine name as reported by "id name".
+	moveTime  int64           // Time per move in milliseconds.
+	info      ClientInfo      // Search information for the last move.
+	command   *exec.Cmd
+	stdin     io.WriteCloser
+	lines     chan string     // Lines of engine output; closed when the engine exits.
+}
+
+// Starts external engine and performs the "uci" and "isready" handshake.
+func NewClient(path string, moveTime int64, args ...string) (*Client, error) {
+	client := &Client{ name: path, moveTime: moveTime, lines: make(chan string, 64) }
+	client.command = exec.Command(path, args...)
+
+	stdin, err := client.command.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := client.command.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err = client.command.Start(); err != nil {
+		return nil, err
+	}
+	client.stdin = stdin
+
+	// Keep reading engine output so that it never blocks on writing.
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			client.lines <- strings.TrimSpace(scanner.Text())
+		}
+		close(client.lines)
+	}()
+
+	if err = client.send("uci\n"); err == nil {
+		_, err = client.expect(`uciok`, clientGrace * 5)
+	}
+	if err == nil {
+		err = client.ready()
+	}
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// Sets engine option, ex. "Hash" or "Threads".
+func (c *Client) SetOption(name, value string) error {
+	if err := c.send("setoption name %s value %s\n", name, value); err != nil {
+		return err
+	}
+	return c.ready()
+}
+
+// Tells the engine that the next position is from a different game.
+func (c *Client) newGame() error {
+	if err := c.send("ucinewgame\n"); err != nil {
+		return err
+	}
+	return c.ready()
+}
+
+// Sends the game position as initial FEN followed by the moves, and waits for
+// the engine to come up with the best move. If the engine exceeds its time we
+// send "stop", and if it doesn't respond even then it forfeits on time.
+func (c *Client) bestMove(p *Position, fen string, moves []Move) (Move, error) {
+	position := `position fen ` + fen
+	if len(moves) > 0 {
+		position += ` moves`
+		for _, move := range moves {
+			position += ` ` + move.notation()
+		}
+	}
+	if err := c.send("%s\ngo movetime %d\n", position, c.moveTime); err != nil {
+		return Move(0), err
+	}
+
+	c.info = ClientInfo{}
+	line, err := c.expect(`bestmove`, c.moveTime + clientGrace)
+	if err != nil {
+		if err = c.send("stop\n"); err == nil {
+			line, err = c.expect(`bestmove`, clientGrace)
+		}
+		if err != nil {
+			return Move(0), fmt.Errorf("%s: lost on time: %s", c.name, err)
+		}
+	}
+
+	// The move must be valid in the position, including "0000" for no move.
+	notation := ``
+	if fields := strings.Fields(line); len(fields) > 1 {
+		notation = fields[1]
+	}
+	if len(notation) >= 4 && len(notation) <= 5 {
+		move := NewMoveFromNotation(p, notation)
+		if NewGen(p, MaxPly).generateAllMoves().validOnly().amongValid(move) {
+			return move, nil
+		}
+	}
+	return Move(0), fmt.Errorf("%s: invalid move %q in %s", c.name, notation, p.fen())
+}
+
+// Stops the engine asking it to quit first and killing it if it doesn't.
+func (c *Client) Close() error {
+	c.send("quit\n")
+	c.stdin.Close()
+
+	done := make(chan error, 1)
+	go func() { done <- c.command.Wait() }()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(time.Millisecond * clientGrace):
+		return c.command.Process.Kill()
+	}
+}
+
+func (c *Client) send(format string, args ...interface{}) error {
+	_, err := fmt.Fprintf(c.stdin, format, args...)
+	return err
+}
+
+// Sends "isready" and waits for "readyok".
+func (c *Client) ready() error {
+	if err := c.send("isready\n"); err != nil {
+		return err
+	}
+	_, err := c.expect(`readyok`, clientGrace * 5)
+	return err
+}
+
+// Reads engine output until the line that starts with the given token, parsing
+// "id" and "info" lines along the way. Returns error if the engine exits or does
+// not respond within the timeout in milliseconds.
+func (c *Client) expect(token string, timeout int64) (string, error) {
+	deadline := time.After(time.Millisecond * time.Duration(timeout))
+	for {
+		select {
+		case line, ok := <-c.lines:
+			if !ok {
+				return ``, fmt.Errorf("%s: engine has exited", c.name)
+			}
+			fields := strings.Fields(line)
+			if len(fields) == 0 {
+				continue
+			}
+			if fields[0] == token {
+				return line, nil
+			}
+			if fields[0] == `info` {
+				c.parseInfo(fields[1:])
+			} else if fields[0] == `id` && len(fields) > 2 && fields[1] == `name` {
+				c.name = strings.Join(fields[2:], ` `)
+			}
+		case <-deadline:
+			return ``, fmt.Errorf("%s: no %q within %dms", c.name, token, timeout)
+		}
+	}
+}
+
+// Parses "info" line arguments. Only the lines with the score update search
+// information, and the rest, ex. "info currmove" or "info string", are ignored.
+func (c *Client) parseInfo(args []string) {
+	info, scored := ClientInfo{}, false
+	for i := 0; i+1 < len(args); i++ {
+		switch args[i] {
+		case `depth`:
+			info.depth, _ = strconv.Atoi(args[i+1])
+		case `nodes`:
+			info.nodes, _ = strconv.ParseInt(args[i+1], 10, 64)
+		case `score`:
+			if i+2 < len(args) {
+				n, err := strconv.Atoi(args[i+2])
+				if scored = err == nil; !scored {
+					return
+				}
+				if args[i+1] == `cp` {
+					info.score = n * onePawn / 100
+				} else if n > 0 { // Mate in n moves.
+					info.score = Checkmate - 2 * n + 1
+				} else {
+					info.score = -Checkmate - 2 * n
+				}
+			}
+		case `pv`:
+			info.pv = args[i+1:]
+			i = len(args)
+		case `string`:
+			return // The rest of the line is arbitrary text.
+		}
+	}
+	if scored {
+		c.info = info
+	}
+}
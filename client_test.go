@@ -0,0 +1,93 @@
+package kingside
+
+import `testing`
+
+func TestClientBestMove(t *testing.T) {
+	client, err := NewClient(`scripts/mock.sh`, 100, `e2e4`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	position, _ := NewGame().start()
+	move, err := client.bestMove(position, initialFEN, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.name != `mock` || move.notation() != `e2e4` {
+		t.Errorf("unexpected name %q or move %s", client.name, move.notation())
+	}
+	if client.info.depth != 1 || client.info.score != 10 || len(client.info.pv) != 1 {
+		t.Errorf("unexpected info %+v", client.info)
+	}
+}
+
+func TestClientInvalidMove(t *testing.T) {
+	client, err := NewClient(`scripts/mock.sh`, 100, `e2e5`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	position, _ := NewGame().start()
+	if _, err := client.bestMove(position, initialFEN, nil); err == nil {
+		t.Errorf("expected invalid move error")
+	}
+}
+
+func TestClientTimeout(t *testing.T) {
+	client, err := NewClient(`scripts/mock.sh`, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	position, _ := NewGame().start()
+	if _, err := client.bestMove(position, initialFEN, nil); err == nil {
+		t.Errorf("expected time forfeit")
+	}
+}
+
+// External engine loses the games where it makes invalid move.
+func TestMatchForfeit(t *testing.T) {
+	NewEngine(`depth`, 1)
+	epd, _ := NewEpd(initialFEN)
+	m := &Match{ openings: []*Epd{ epd } }
+	m.engines[0], m.engines[0].quiet = engine, true
+
+	client, err := NewClient(`scripts/mock.sh`, 100, `e2e5`, `e7e4`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if err := m.Versus(client).pair(0, epd); err != nil {
+		t.Fatal(err)
+	}
+	if m.wins != 2 || m.draws != 0 || m.losses != 0 {
+		t.Errorf("expected two wins, got %s", m)
+	}
+}
+
+// Draws by the rules are caught right after external engine move even if it reports
+// non-zero score.
+func TestMatchClientDraw(t *testing.T) {
+	NewEngine(`depth`, 1)
+	epd, _ := NewEpd(`7k/8/6r1/8/8/8/8/KN6 w - - 98 80`)
+	m := &Match{ openings: []*Epd{ epd } }
+	m.engines[0], m.engines[0].quiet = engine, true
+
+	client, err := NewClient(`scripts/mock.sh`, 100, `h8h7`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	status, _, err := m.Versus(client).play(epd, 0)
+	if err != nil || status != FiftyMoves {
+		t.Errorf("expected draw by fifty moves rule, got %d %v", status, err)
+	}
+	if len(game.moves) != 2 {
+		t.Errorf("expected the game to end right after external engine move, got %d moves", len(game.moves))
+	}
+}
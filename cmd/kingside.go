@@ -3,18 +3,82 @@ package main
 
 import (
 	kingside `../`
+	`flag`
+	`fmt`
 	`os`
 	`runtime`
 )
 
 // Ignore previous comment.
 func main() {
+	interactive := flag.Bool(`i`, false, `play in interactive mode`)
+	book := flag.String(`book`, ``, `polyglot opening book file`)
+	epd := flag.String(`epd`, ``, `run test suite from EPD file`)
+	movetime := flag.Int(`movetime`, 5000, `time per move in milliseconds`)
+	depth := flag.Int(`depth`, 0, `search depth limit`)
+	match := flag.String(`match`, ``, `play self-play match using openings from EPD file`)
+	movetime2 := flag.Int(`movetime2`, 0, `time per move for the second engine in the match`)
+	depth2 := flag.Int(`depth2`, 0, `search depth limit for the second engine in the match`)
+	opponent := flag.String(`engine`, ``, `external UCI engine to play the match against`)
+	pgn := flag.String(`pgn`, ``, `PGN file to save the match games to`)
+	sprt := flag.Bool(`sprt`, false, `run the match as sequential probability ratio test`)
+	elo0 := flag.Float64(`elo0`, 0, `SPRT null hypothesis Elo difference`)
+	elo1 := flag.Float64(`elo1`, 5, `SPRT alternative hypothesis Elo difference`)
+	alpha := flag.Float64(`alpha`, 0.05, `SPRT false positive probability`)
+	beta := flag.Float64(`beta`, 0.05, `SPRT false negative probability`)
+	games := flag.Int(`games`, 20000, `SPRT games limit`)
+	flag.Parse()
+
+	if *book != `` {
+		if _, err := os.Stat(*book); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
 	engine := kingside.NewEngine(
 		`fancy`, runtime.GOOS == `darwin`,
-		`movetime`, 5000,
+		`movetime`, *movetime,
+		`depth`, *depth,
+		`book`, *book,
 	)
 
-	if len(os.Args) > 1 && os.Args[1] == `-i` {
+	if *match != `` {
+		// NewEngine() returns the same engine so make a copy of the first one.
+		first := *engine
+		if *movetime2 == 0 {
+			*movetime2 = *movetime
+		}
+		second := *kingside.NewEngine(
+			`movetime`, *movetime2,
+			`depth`, *depth2,
+			`book`, *book,
+		)
+		m, err := kingside.NewMatch(*match, first, second)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if *opponent != `` {
+			client, err := kingside.NewClient(*opponent, int64(*movetime2))
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			defer client.Close()
+			m.Versus(client)
+		}
+		if *pgn != `` {
+			m.Save(*pgn)
+		}
+		if *sprt {
+			m.Sprt(kingside.NewSprt(*elo0, *elo1, *alpha, *beta).Limit(*games))
+		} else {
+			m.Play()
+		}
+	} else if *epd != `` {
+		engine.Epd(*epd)
+	} else if *interactive {
 		engine.Repl()
 	} else {
 		engine.Uci()
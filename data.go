@@ -107,6 +107,11 @@ var maskIsolated = [8]Bitmask{
 
 var eight = [2]int{ 8, -8 }
 
+// Game phase weights of the pieces. Their sum gives position's material balance
+// index: 24 in the initial position down to 0 when only kings and pawns remain.
+const maxBalance = 24
+var materialBalance = [14]int{ 0, 0, 0, 0, 1, 1, 1, 1, 2, 2, 4, 4, 0, 0 }
+
 // Castle squares that should be *empty* in order for the castle to be valid.
 var gapKing = [2]Bitmask{
 	bit[F1]|bit[G1], bit[F8]|bit[G8],
@@ -2,24 +2,29 @@ package kingside
 
 import (
 	`fmt`
+	`math`
 	`os`
+	`sync/atomic`
 	`time`
 )
 
+// Polling interval in milliseconds to check whether the search should be stopped.
+const Ping = 50
+
 type Clock struct {
-	halt        bool     // Stop search immediately when set to true.
+	halt        int32    // Stop search immediately when set to 1, accessed atomically.
 	softStop    int64    // Target soft time limit to make a move.
 	hardStop    int64    // Immediate stop time limit.
 	extra       float32  // Extra time factor based on search volatility.
 	start       time.Time
-	ticker      *time.Ticker
+	done        chan bool // Closed when the search is over to stop polling the clock.
 }
 
 type Options struct {
 	ponder      bool     // (-) Pondering mode.
-	infinite    bool     // (-) Search until the "stop" command.
+	infinite    bool     // Search until the "stop" command.
 	maxDepth    int      // Search X plies only.
-	maxNodes    int      // (-) Search X nodes only.
+	maxNodes    int      // Search X nodes only.
 	moveTime    int64    // Search exactly X milliseconds per move.
 	movesToGo   int64    // Number of moves to make till time control.
 	timeLeft    int64    // Time left for all remaining moves.
@@ -30,7 +35,11 @@ type Engine struct {
 	log         bool     // Enable logging.
 	uci         bool     // Use UCI protocol.
 	fancy       bool     // Represent pieces as UTF-8 characters.
+	quiet       bool     // Don't report search progress and best move.
 	status      uint8    // Engine status.
+	cacheSize   int      // Transposition table size in megabytes.
+	ownBook     bool     // Pick moves from the opening book when available.
+	book        *Book    // Opening book if any.
 	clock       Clock
 	options     Options
 }
@@ -39,7 +48,7 @@ type Engine struct {
 var engine Engine
 
 func NewEngine(args ...interface{}) *Engine {
-	engine = Engine{}
+	engine = Engine{ cacheSize: 64 }
 	for i := 0; i < len(args); i += 2 {
 		switch value := args[i+1]; args[i] {
 		case `uci`:
@@ -50,8 +59,15 @@ func NewEngine(args ...interface{}) *Engine {
 			engine.options.maxDepth = value.(int)
 		case `movetime`:
 			engine.options.moveTime = int64(value.(int))
+		case `cache`:
+			engine.cacheSize = value.(int)
+		case `book`:
+			if book, err := NewBook(value.(string)); err == nil {
+				engine.book, engine.ownBook = book, true
+			}
 		}
 	}
+	NewCache(engine.cacheSize)
 
 	return &engine
 }
@@ -76,3 +92,117 @@ func (e *Engine) limits(options Options) *Engine {
 	e.options = options
 	return e
 }
+
+// Starts the clock for the move we're about to make. For fixed time per move both
+// soft and hard limits are the same; otherwise they get derived from the time
+// left on the clock. Infinite search and pondering have no time limits at all.
+func (e *Engine) startClock() *Engine {
+	e.stopClock()
+	e.clock.resume()
+	e.clock.start = time.Now()
+	e.clock.extra = 0.0
+	e.clock.softStop, e.clock.hardStop = 0, 0
+
+	if e.options.infinite || e.options.ponder {
+		return e
+	}
+	if e.options.moveTime > 0 {
+		e.clock.softStop = e.options.moveTime
+		e.clock.hardStop = e.options.moveTime
+	} else if e.options.timeLeft > 0 {
+		e.varyingLimits(e.options)
+	} else {
+		return e
+	}
+
+	// Poll the clock and set the halt flag as soon as the search exceeds
+	// its hard time limit. The poller quits when the search is over.
+	ticker, done := time.NewTicker(time.Millisecond * Ping), make(chan bool)
+	e.clock.done = done
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				if e.clock.halted() {
+					return
+				}
+				if now.Sub(e.clock.start).Nanoseconds() / 1000000 >= e.clock.hardStop {
+					e.clock.stop()
+					return
+				}
+			}
+		}
+	}()
+
+	return e
+}
+
+func (e *Engine) stopClock() *Engine {
+	if e.clock.done != nil {
+		close(e.clock.done)
+		e.clock.done = nil
+	}
+	return e
+}
+
+// Sets the halt flag. The flag gets set by the UCI command reader and the clock
+// poller while the search goroutine keeps checking it, hence atomic access.
+func (c *Clock) stop() {
+	atomic.StoreInt32(&c.halt, 1)
+}
+
+// Clears the halt flag before starting new search.
+func (c *Clock) resume() {
+	atomic.StoreInt32(&c.halt, 0)
+}
+
+// Returns true if the search should be stopped immediately.
+func (c *Clock) halted() bool {
+	return atomic.LoadInt32(&c.halt) != 0
+}
+
+// Returns true if the iterative deepening should not start next iteration
+// since we're likely to run out of time before it completes. Fixed time per
+// move is always searched until the hard limit.
+func (e *Engine) softLimit() bool {
+	if e.clock.softStop == 0 || e.options.moveTime > 0 {
+		return false
+	}
+	return since(e.clock.start) >= int64(float32(e.clock.softStop) * (1.0 + e.clock.extra))
+}
+
+// Adjusts the extra time factor depending on whether the best move has changed
+// since the previous iteration: unstable search gets more time while stable
+// one gradually returns back to the original soft limit.
+func (e *Engine) volatility(changed bool) *Engine {
+	if changed {
+		e.clock.extra = float32(math.Min(float64(e.clock.extra) + 0.5, 1.5))
+	} else {
+		e.clock.extra /= 2.0
+	}
+	return e
+}
+
+// Splits the remaining time evenly between the moves left till the time control
+// (or assumed number of moves for sudden death). Hard limit allows to exceed the
+// soft one several times but never eats up the time left on the clock.
+func (e *Engine) varyingLimits(options Options) *Engine {
+	moves := options.movesToGo
+	if moves == 0 {
+		moves = 40
+	}
+
+	reserve := min64(options.timeLeft / 10, 1000) // Safety margin for I/O lag.
+	available := max64(options.timeLeft - reserve, 1)
+
+	e.clock.softStop = min64(available / moves + options.timeInc * 3 / 4, available)
+	e.clock.hardStop = min64(e.clock.softStop * 4, available)
+	if moves == 1 {
+		e.clock.softStop = e.clock.hardStop
+	}
+
+	return e
+}
@@ -1,8 +1,13 @@
 package kingside
 
 import(
+	`bufio`
 	`fmt`
+	`io/ioutil`
+	`os`
 	`runtime`
+	`strconv`
+	`strings`
 )
 
 var (
@@ -12,12 +17,27 @@ var (
 	ansiNone  = "\033[0m"
 )
 
-func (e *Engine) replBestMove(move Move) *Engine {
+func (e *Engine) replBestMove(move Move, pv []Move) *Engine {
+	if move == Move(0) { // Checkmate or stalemate: nothing to report.
+		return e
+	}
 	fmt.Printf(ansiTeal + "kingside's move: %s", move)
+	if len(pv) > 1 {
+		fmt.Printf("\nprincipal variation: %s", replPrincipal(pv))
+	}
 	fmt.Println(ansiNone + "\n")
 	return e
 }
 
+// Formats principal variation as space separated moves in long algebraic notation.
+func replPrincipal(pv []Move) string {
+	moves := make([]string, len(pv))
+	for i, move := range pv {
+		moves[i] = move.String()
+	}
+	return strings.Join(moves, ` `)
+}
+
 // "There are two types of command interfaces in the world of computing: good
 // interfaces and user interfaces." -- Daniel J. Bernstein
 func (e *Engine) Repl() *Engine {
@@ -32,7 +52,7 @@ func (e *Engine) Repl() *Engine {
 	setup := func() {
 		if game == nil || position == nil {
 			game = NewGame()
-			position = game.start()
+			position, _ = game.start()
 			fmt.Printf("%s\n", position)
 		}
 	}
@@ -40,14 +60,26 @@ func (e *Engine) Repl() *Engine {
 	think := func() {
 		if move := game.Think(); move != 0 {
 			position = position.makeMove(move)
+			game.record(move, game.analysis())
 			fmt.Printf("%s\n", position)
 		}
 	}
 
 	fmt.Printf("kingside\nType ? for help.\n\n")
-	for command, parameter := ``, ``; ; command, parameter = ``, `` {
+	bio := bufio.NewReader(os.Stdin)
+	for {
 		fmt.Print(`kingside> `)
-		fmt.Scanln(&command, &parameter)
+		line, err := bio.ReadString('\n')
+		if err != nil && line == `` {
+			return e
+		}
+
+		// The first word is the command, and the rest of the line is its
+		// parameter.
+		command, parameter := ``, ``
+		if words := strings.Fields(line); len(words) > 0 {
+			command, parameter = words[0], strings.Join(words[1:], ` `)
+		}
 
 		switch command {
 		case ``:
@@ -57,25 +89,78 @@ func (e *Engine) Repl() *Engine {
 			setup()
 			think()
 		case `help`, `?`:
-			fmt.Println("The commands are:\n\n" +
+			fmt.Print("The commands are:\n\n" +
 				"  exit           Exit the program\n" +
 				"  go             Take side and make a move\n" +
 				"  help           Display this help\n" +
+				"  load <file>    Load the last game from PGN file\n" +
 				"  new            Start new game\n" +
+				"  perft <depth>  Count move tree nodes for each move\n" +
+				"  perft suite    Verify move generator using standard positions\n" +
+				"  save <file>    Save the game to PGN file\n" +
+				"  setup <fen>    Set up position from FEN or Donna chess format\n" +
 				"  undo           Undo last move\n\n" +
-				"To make a move use algebraic notation, for example e2e4, Ng1f3, or e7e8Q\n")
+				"To make a move use algebraic notation, for example e2e4, Ng1f3, Nf3, O-O, or e8=Q\n\n")
 		case `new`:
 			game, position = nil, nil
 			setup()
+		case `load`:
+			games, err := NewPgnFromFile(parameter)
+			if err == nil && len(games) == 0 {
+				err = fmt.Errorf("no games in '%s'", parameter)
+			}
+			if err != nil {
+				fmt.Printf(ansiRed + "%s\n" + ansiNone, err)
+				game, position = nil, nil // Parsing has clobbered the move tree.
+			} else if game, position, err = games[len(games)-1].replay(); err != nil {
+				fmt.Printf(ansiRed + "%s\n" + ansiNone, err)
+			} else {
+				fmt.Printf("%s\n", position)
+			}
+		case `perft`:
+			if parameter == `suite` {
+				PerftSuite()
+				game, position = nil, nil
+			} else if depth, err := strconv.Atoi(parameter); err == nil && depth > 0 {
+				setup()
+				position.divide(depth)
+			} else {
+				fmt.Println("Usage: perft <depth> | perft suite")
+			}
+		case `save`:
+			if game == nil || position == nil {
+				fmt.Printf("%sNo game to save%s\n", ansiRed, ansiNone)
+				break
+			}
+			// The engine plays the side its moves have comments for.
+			tags := map[string]string{ `Event`: `kingside game` }
+			for i, comment := range game.comments {
+				if comment != `` {
+					tags[[]string{ `White`, `Black` }[game.moves[i].color()]] = `kingside`
+				}
+			}
+			if err := ioutil.WriteFile(parameter, []byte(game.pgn(position, tags)), 0644); err != nil {
+				fmt.Printf(ansiRed + "%s\n" + ansiNone, err)
+			}
+		case `setup`:
+			game = NewGame(parameter)
+			if position, err = game.start(); err != nil {
+				fmt.Printf(ansiRed + "Invalid position: %s" + ansiNone + "\n", err)
+				game, position = nil, nil
+			} else {
+				fmt.Printf("%s\n", position)
+			}
 		case `undo`:
 			if position != nil {
 				position = position.undoLastMove()
+				game.undo()
 				fmt.Printf("%s\n", position)
 			}
 		default:
 			setup()
 			if move, validMoves := NewMoveFromString(position, command); move != 0 {
 				position = position.makeMove(move)
+				game.record(move, ``)
 				think()
 			} else { // Invalid move or non-evasion on check.
 				fancy := e.fancy; e.fancy = false
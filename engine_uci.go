@@ -7,9 +7,10 @@ import (
 	`os`
 	`strconv`
 	`strings`
+	`sync`
 )
 
-func (e *Engine) uciScore(depth, score, alpha, beta int) *Engine {
+func (e *Engine) uciScore(depth, score, alpha, beta int, pv []Move) *Engine {
 	str := fmt.Sprintf("info depth %d score", depth)
 
 	if abs(score) < Checkmate-MaxPly {
@@ -26,6 +27,13 @@ func (e *Engine) uciScore(depth, score, alpha, beta int) *Engine {
 	} else if score >= beta {
 		str += " lowerbound"
 	}
+	str += e.uciStats()
+	if len(pv) > 0 {
+		str += " pv"
+		for _, move := range pv {
+			str += " " + move.notation()
+		}
+	}
 
 	return engine.reply(str + "\n")
 }
@@ -35,7 +43,21 @@ func (e *Engine) uciMove(move Move, moveno, depth int) *Engine {
 }
 
 func (e *Engine) uciBestMove(move Move, duration int64) *Engine {
-	return engine.reply("info nodes %d time %d\nbestmove %s\n", 0, duration, move.notation())
+	notation := `0000` // No move in checkmate or stalemate.
+	if move != Move(0) {
+		notation = move.notation()
+	}
+	return engine.reply("info%s\nbestmove %s\n", e.uciStats(), notation)
+}
+
+// Formats search statistics reported in every "info" line.
+func (e *Engine) uciStats() string {
+	duration := since(e.clock.start)
+	str := fmt.Sprintf(" seldepth %d nodes %d nps %d time %d", stats.seldepth, stats.total(), stats.nps(duration), duration)
+	if len(cache.entries) > 0 {
+		str += fmt.Sprintf(" hashfull %d", cache.usage())
+	}
+	return str
 }
 
 // Brain-damaged universal chess interface (UCI) protocol as described at
@@ -46,17 +68,40 @@ func (e *Engine) Uci() *Engine {
 
 	e.uci = true
 
+	// Each "go" command starts from the engine's default limits so that
+	// nothing, ex. "infinite", carries over from the previous search.
+	defaults := e.options
+
+	// The search runs in its own goroutine so that we could keep reading
+	// commands like "stop" or "isready" while thinking.
+	var searching sync.WaitGroup
+
+	// Halts current search if any and waits for it to report the best move.
+	stopSearch := func() {
+		e.clock.stop()
+		searching.Wait()
+	}
+
 	// "uci" command handler.
 	doUci := func(args []string) {
 		e.reply("kingside\n")
 		e.reply("id name kingside\n")
 		e.reply("id author The kingside team\n")
+		e.reply("option name Hash type spin default %d min 0 max 4096\n", e.cacheSize)
+		e.reply("option name OwnBook type check default %t\n", e.ownBook)
+		if e.book != nil {
+			e.reply("option name BookFile type string default %s\n", e.book.fileName)
+		} else {
+			e.reply("option name BookFile type string default <empty>\n")
+		}
 		e.reply("uciok\n")
 	}
 
 	// "ucinewgame" command handler.
 	doUciNewGame := func(args []string) {
+		stopSearch()
 		game, position = nil, nil
+		cache.clear()
 	}
 
 	// "isready" command handler.
@@ -66,15 +111,22 @@ func (e *Engine) Uci() *Engine {
 
 	// "position [startpos | fen ] [ moves ... ]" command handler.
 	doPosition := func(args []string) {
+		stopSearch()
+
 		// Make sure we've started the game since "ucinewgame" is optional.
 		if game == nil || position == nil {
 			game = NewGame()
 		}
 
+		var err error
+		if len(args) == 0 {
+			return
+		}
 		switch args[0] {
 		case `startpos`:
 			args = args[1:]
-			position = game.start()
+			game = NewGame()
+			position, err = game.start()
 		case `fen`:
 			fen := []string{}
 			for _, token := range args[1:] {
@@ -85,23 +137,43 @@ func (e *Engine) Uci() *Engine {
 				fen = append(fen, token)
 			}
 			game.initial = strings.Join(fen, ` `)
-			position = game.start()
+			position, err = game.start()
 		default:
 			return
 		}
 
-		if position != nil && len(args) > 0 && args[0] == `moves` {
-			for _, move := range args[1:] {
-				args = args[1:] // Shift the move.
-				position = position.makeMove(NewMoveFromNotation(position, move))
+		// Report invalid position or move instead of crashing; the position
+		// remains unset until the next valid "position" command.
+		if err != nil {
+			e.reply("info string %s\n", err)
+			position = nil
+			return
+		}
+
+		if len(args) > 0 && args[0] == `moves` {
+			for _, notation := range args[1:] {
+				move, _ := NewMoveFromString(position, notation)
+				if move == Move(0) {
+					e.reply("info string invalid move %s in %s\n", notation, position.fen())
+					position = nil
+					return
+				}
+				position = position.makeMove(move)
+				game.record(move, ``)
 			}
 		}
 	}
 
-	// "go [[wtime winc | btime binc ] movestogo] | depth | nodes | movetime"
+	// "go [[wtime winc | btime binc ] movestogo] | depth | nodes | movetime | perft"
 	doGo := func(args []string) {
+		stopSearch()
+		if position == nil {
+			e.reply("bestmove 0000\n") // Nothing to search.
+			return
+		}
+
 		think := true
-		options := e.options
+		options := defaults
 
 		for i, token := range args {
 			// Boolen "infinite" and "ponder" commands have no arguments.
@@ -113,6 +185,11 @@ func (e *Engine) Uci() *Engine {
 				think = false
 			} else if len(args) > i+1 {
 				switch token {
+				case `perft`: // <-- Custom token to verify move generator.
+					if n, err := strconv.Atoi(args[i+1]); err == nil && n > 0 {
+						position.divide(n)
+					}
+					return
 				case `depth`:
 					if n, err := strconv.Atoi(args[i+1]); err == nil {
 						options = Options{ maxDepth: n }
@@ -156,18 +233,65 @@ func (e *Engine) Uci() *Engine {
 				}
 			}
 		}
+
+		// Time left on the clock takes precedence over default time per move.
+		if options.timeLeft > 0 {
+			options.moveTime = 0
+		}
 		e.limits(options)
 
 		// Start "thinking" and come up with best move unless when running
-		// tests where we verify argument parsing only.
+		// tests where we verify argument parsing only. The clock gets started
+		// before spawning the search so that "stop" can't get lost.
 		if think {
-			game.Think()
+			e.startClock()
+			searching.Add(1)
+			go func() {
+				defer searching.Done()
+				game.deepening()
+			}()
+		}
+	}
+
+	// "setoption name <id> [value <x>]" command handler.
+	doSetOption := func(args []string) {
+		stopSearch()
+
+		name, value := []string{}, []string{}
+		for i := 0; i < len(args); i++ {
+			if args[i] == `name` {
+				for i++; i < len(args) && args[i] != `value`; i++ {
+					name = append(name, args[i])
+				}
+				i--
+			} else if args[i] == `value` {
+				value = args[i+1:]
+				break
+			}
+		}
+
+		switch strings.ToLower(strings.Join(name, ` `)) {
+		case `hash`:
+			if n, err := strconv.Atoi(strings.Join(value, ` `)); err == nil && n >= 0 {
+				e.cacheSize = n
+				NewCache(n)
+			}
+		case `ownbook`:
+			e.ownBook = strings.Join(value, ` `) == `true`
+		case `bookfile`:
+			if fileName := strings.Join(value, ` `); fileName == `` || fileName == `<empty>` {
+				e.book = nil
+			} else if book, err := NewBook(fileName); err == nil {
+				e.book = book
+			} else {
+				e.reply("info string %s\n", err)
+			}
 		}
 	}
 
 	// Stop calculating as soon as possible.
 	doStop := func(args []string) {
-		e.clock.halt = true
+		stopSearch()
 	}
 
 	var commands = map[string]func([]string){
@@ -175,6 +299,7 @@ func (e *Engine) Uci() *Engine {
 		`uci`:        doUci,
 		`ucinewgame`: doUciNewGame,
 		`position`:   doPosition,
+		`setoption`:  doSetOption,
 		`go`:         doGo,
 		`stop`:       doStop,
 	}
@@ -195,7 +320,10 @@ func (e *Engine) Uci() *Engine {
 			if handler, ok := commands[args[0]]; ok {
 				handler(args[1:])
 			}
+		} else if err == io.EOF {
+			break
 		}
 	}
+	stopSearch()
 	return e
 }
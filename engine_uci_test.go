@@ -0,0 +1,68 @@
+package kingside
+
+import (
+	`io/ioutil`
+	`os`
+	`strings`
+	`testing`
+	`time`
+)
+
+// Feeds the commands to UCI loop running the search in its own goroutine and
+// returns engine output. Run with -race to catch unsynchronized access.
+func uciSession(t *testing.T, commands ...string) string {
+	stdin, stdout := os.Stdin, os.Stdout
+	defer func() { os.Stdin, os.Stdout = stdin, stdout }()
+
+	inReader, inWriter, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	outReader, outWriter, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdin, os.Stdout = inReader, outWriter
+
+	output := make(chan string)
+	go func() {
+		data, _ := ioutil.ReadAll(outReader)
+		output <- string(data)
+	}()
+	go func() {
+		for _, command := range commands {
+			if strings.HasPrefix(command, `sleep `) {
+				duration, _ := time.ParseDuration(command[6:])
+				time.Sleep(duration)
+			} else {
+				inWriter.WriteString(command + "\n")
+			}
+		}
+		inWriter.Close()
+	}()
+
+	NewEngine().Uci()
+	outWriter.Close()
+	inReader.Close()
+	return <-output
+}
+
+func TestUciStop(t *testing.T) {
+	output := uciSession(t, `position startpos`, `go infinite`, `sleep 100ms`, `stop`,
+		`go movetime 100`, `sleep 300ms`, `go wtime 1000 btime 1000`, `quit`)
+	if count := strings.Count(output, "\nbestmove "); count != 3 {
+		t.Errorf("expected 3 best moves, got %d:\n%s", count, output)
+	}
+}
+
+func TestUciSelectiveDepth(t *testing.T) {
+	output := uciSession(t, `position startpos`, `go depth 3`, `sleep 500ms`, `quit`)
+	for _, line := range strings.Split(output, "\n") {
+		if strings.Contains(line, ` nodes `) && !strings.Contains(line, ` seldepth `) {
+			t.Errorf("expected seldepth in %q", line)
+		}
+	}
+	if !strings.Contains(output, "\nbestmove ") {
+		t.Errorf("expected best move:\n%s", output)
+	}
+}
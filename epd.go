@@ -0,0 +1,211 @@
+package kingside
+
+import (
+	`bufio`
+	`fmt`
+	`os`
+	`strconv`
+	`strings`
+)
+
+// Extended Position Description: four FEN fields followed by semicolon separated
+// operations, ex. `r1b1k2r/... w kq - bm Nxf7+; id "WAC.001";`
+type Epd struct {
+	fen      string    // Position in FEN format.
+	id       string    // Position identifier ("id" opcode).
+	comment  string    // Primary comment ("c0" opcode).
+	depth    int       // Analysis count depth ("acd" opcode).
+	best     []string  // Best moves in standard algebraic notation ("bm" opcode).
+	avoid    []string  // Moves to avoid in standard algebraic notation ("am" opcode).
+}
+
+// Parses EPD record. The board fields could optionally be followed by half-move
+// clock and full move number as in FEN, or the same values could be given by
+// "hmvc" and "fmvn" opcodes. Unknown opcodes are ignored.
+func NewEpd(line string) (*Epd, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 {
+		return nil, fmt.Errorf("invalid EPD %q", line)
+	}
+
+	epd := &Epd{}
+	board, halfmove, fullmove := fields[:4], `0`, `1`
+	fields = fields[4:]
+	if len(fields) >= 2 && isNumber(fields[0]) && isNumber(fields[1]) {
+		halfmove, fullmove = fields[0], fields[1]
+		fields = fields[2:]
+	}
+
+	for _, operation := range epdOperations(strings.Join(fields, ` `)) {
+		opcode, operands := operation[0], operation[1:]
+		if len(operands) == 0 {
+			continue
+		}
+		switch opcode {
+		case `id`:
+			epd.id = operands[0]
+		case `c0`:
+			epd.comment = operands[0]
+		case `acd`:
+			depth, err := strconv.Atoi(operands[0])
+			if err != nil || depth < 0 {
+				return nil, fmt.Errorf("invalid acd %q", operands[0])
+			}
+			epd.depth = depth
+		case `bm`:
+			epd.best = operands
+		case `am`:
+			epd.avoid = operands
+		case `hmvc`:
+			halfmove = operands[0]
+		case `fmvn`:
+			fullmove = operands[0]
+		}
+	}
+
+	epd.fen = strings.Join(board, ` `) + ` ` + halfmove + ` ` + fullmove
+	return epd, nil
+}
+
+// Reads EPD file skipping blank lines and comments starting with '#'.
+func NewEpdFromFile(fileName string) (records []*Epd, err error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for lineno := 1; scanner.Scan(); lineno++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == `` || line[0] == '#' {
+			continue
+		}
+		epd, err := NewEpd(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %s", fileName, lineno, err)
+		}
+		records = append(records, epd)
+	}
+
+	return records, scanner.Err()
+}
+
+// Returns true if the EPD has best move or avoid move answer to check against.
+func (epd *Epd) hasAnswer() bool {
+	return len(epd.best) > 0 || len(epd.avoid) > 0
+}
+
+// Returns true if the move is among the best moves and is not among the moves
+// to avoid.
+func (epd *Epd) solved(p *Position, move Move) bool {
+	if move == Move(0) {
+		return false
+	}
+	for _, san := range epd.avoid {
+		if avoid, err := NewMoveFromSan(p, san); err == nil && move == avoid {
+			return false
+		}
+	}
+	if len(epd.best) == 0 {
+		return true
+	}
+	for _, san := range epd.best {
+		if best, err := NewMoveFromSan(p, san); err == nil && move == best {
+			return true
+		}
+	}
+	return false
+}
+
+// Splits EPD operations into opcodes and operands. Semicolons and spaces within
+// double quotes are part of the operand.
+func epdOperations(str string) (operations [][]string) {
+	operation, token, quoted := []string{}, ``, false
+	for i := 0; i < len(str); i++ {
+		switch char := str[i]; {
+		case char == '"':
+			quoted = !quoted
+		case quoted:
+			token += string(char)
+		case char == ' ' || char == ';':
+			if token != `` {
+				operation, token = append(operation, token), ``
+			}
+			if char == ';' && len(operation) > 0 {
+				operations, operation = append(operations, operation), []string{}
+			}
+		default:
+			token += string(char)
+		}
+	}
+	if token != `` {
+		operation = append(operation, token)
+	}
+	if len(operation) > 0 {
+		operations = append(operations, operation)
+	}
+	return
+}
+
+func isNumber(str string) bool {
+	_, err := strconv.Atoi(str)
+	return err == nil
+}
+
+// Searches each position from the EPD file and checks the best move against the
+// "bm" and "am" answers. Positions without answers are searched but not scored.
+func (e *Engine) Epd(fileName string) *Engine {
+	records, err := NewEpdFromFile(fileName)
+	if err != nil {
+		return e.reply("%s\n", err)
+	}
+
+	options, quiet := e.options, e.quiet
+	defer func() { e.limits(options); e.quiet = quiet }()
+	e.quiet = true
+
+	solved, failed := 0, 0
+	for i, epd := range records {
+		game := NewGame(epd.fen)
+		position, err := game.start()
+		if err != nil {
+			e.reply("%3d) %s: %s\n", i + 1, epd.fen, err)
+			failed++
+			continue
+		}
+
+		// Analysis depth, if given, limits the search on top of the time.
+		limits := options
+		if epd.depth > 0 {
+			limits.maxDepth = epd.depth
+		}
+		e.limits(limits)
+		cache.clear()
+
+		move := game.Think()
+		result := `-`
+		if epd.hasAnswer() {
+			if epd.solved(position, move) {
+				result = `solved`
+				solved++
+			} else {
+				result = `failed`
+				failed++
+			}
+		}
+
+		answer := []string{}
+		if len(epd.best) > 0 {
+			answer = append(answer, `bm ` + strings.Join(epd.best, ` `))
+		}
+		if len(epd.avoid) > 0 {
+			answer = append(answer, `am ` + strings.Join(epd.avoid, ` `))
+		}
+		e.reply("%3d) %-12s %-16s %-10s %s  %s\n", i + 1, epd.id, strings.Join(answer, ` `), move.str(), ms(since(e.clock.start)), result)
+	}
+
+	total := solved + failed
+	e.reply("\nsolved %d of %d positions (%d%%), %d searched\n", solved, total, solved * 100 / max(total, 1), len(records))
+	return e
+}
@@ -0,0 +1,53 @@
+package kingside
+
+import `testing`
+
+func TestEpdOpcodes(t *testing.T) {
+	epd, err := NewEpd(`r1b1k2r/ppppnppp/2n2q2/2b5/3NP3/2P1B3/PP3PPP/RN1QKB1R w KQkq - bm Nb5 Qd2; am Nxc6; id "test; one"; c0 "two words"; acd 5;`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if epd.fen != `r1b1k2r/ppppnppp/2n2q2/2b5/3NP3/2P1B3/PP3PPP/RN1QKB1R w KQkq - 0 1` {
+		t.Errorf("unexpected FEN %s", epd.fen)
+	}
+	if epd.id != `test; one` || epd.comment != `two words` || epd.depth != 5 {
+		t.Errorf("unexpected id %q, c0 %q, or acd %d", epd.id, epd.comment, epd.depth)
+	}
+	if len(epd.best) != 2 || epd.best[0] != `Nb5` || epd.best[1] != `Qd2` || len(epd.avoid) != 1 || epd.avoid[0] != `Nxc6` {
+		t.Errorf("unexpected bm %v or am %v", epd.best, epd.avoid)
+	}
+}
+
+func TestEpdMoveCounters(t *testing.T) {
+	epd, _ := NewEpd(`rnbqkb1r/1p3ppp/p2ppn2/8/3NP3/2N1BP2/PPP3PP/R2QKB1R b KQkq - 0 7`)
+	if epd.fen != `rnbqkb1r/1p3ppp/p2ppn2/8/3NP3/2N1BP2/PPP3PP/R2QKB1R b KQkq - 0 7` {
+		t.Errorf("unexpected FEN %s", epd.fen)
+	}
+	epd, _ = NewEpd(`8/8/8/8/8/8/8/K6k w - - hmvc 12; fmvn 40;`)
+	if epd.fen != `8/8/8/8/8/8/8/K6k w - - 12 40` {
+		t.Errorf("unexpected FEN %s", epd.fen)
+	}
+}
+
+func TestEpdInvalid(t *testing.T) {
+	if _, err := NewEpd(`8/8/8 w`); err == nil {
+		t.Errorf("expected error for truncated EPD")
+	}
+	if _, err := NewEpd(`8/8/8/8/8/8/8/K6k w - - acd x;`); err == nil {
+		t.Errorf("expected error for invalid acd")
+	}
+}
+
+func TestEpdSolved(t *testing.T) {
+	epd, _ := NewEpd(`6k1/5ppp/8/8/8/8/5PPP/R5K1 w - - bm Ra8#; am Ra7;`)
+	position, err := NewGame(epd.fen).start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !epd.solved(position, NewMove(position, A1, A8)) {
+		t.Errorf("expected Ra8 to solve")
+	}
+	if epd.solved(position, NewMove(position, A1, A7)) || epd.solved(position, NewMove(position, G2, G3)) {
+		t.Errorf("expected Ra7 and g3 to fail")
+	}
+}
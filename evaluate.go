@@ -11,7 +11,9 @@ const (
 )
 
 type Evaluation struct {
-	score     int            // Current score.
+	score     Score          // Current score.
+	phase     int            // Game phase based on material balance.
+	pawns     *PawnEntry     // Pawn structure cache entry for the position.
 	attacks   [14]Bitmask    // Attack bitmasks for all the pieces on the board.
 	position  *Position      // Pointer to the position we're evaluating.
 }
@@ -29,7 +31,8 @@ func (e *Evaluation) init(p *Position) *Evaluation {
 	eval = Evaluation{}
 	e.position = p
 
-	e.score = 0
+	e.score = Score{}
+	e.phase = min(p.balance, maxBalance)
 
 	// Set up king and pawn attacks for both sides.
 	e.attacks[King] = p.kingAttacks(White)
@@ -45,22 +48,28 @@ func (e *Evaluation) init(p *Position) *Evaluation {
 }
 
 func (e *Evaluation) run() int {
-	board := e.position.board
-	for board != 0 {
-		square := board.pop()
-		piece := e.position.pieces[square]
-		if piece.color() == e.position.color {
-			e.score += onePawn // TODO
-		} else {
-			e.score -= onePawn
-		}
-	}
+	e.analyzeMaterial()
+	e.analyzePawns()
+	e.analyzePieces()
+	e.analyzeSafety()
+	e.analyzePassers()
 
-	// Flip the sign for black so that evaluation score always
-	// represents the white side.
+	// Blend midgame and endgame scores, and flip the sign for black so that
+	// evaluation score always represents the side to move.
+	score := e.score.blended(e.phase)
 	if e.position.color == Black {
-		e.score = -e.score
+		score = -score
 	}
 
-	return e.score
+	return score
+}
+
+// Adds up material and piece/square bonuses for all the pieces on the board.
+func (e *Evaluation) analyzeMaterial() *Evaluation {
+	board := e.position.board
+	for board != 0 {
+		square := board.pop()
+		e.score.add(pst[e.position.pieces[square]][square])
+	}
+	return e
 }
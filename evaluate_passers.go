@@ -0,0 +1,68 @@
+package kingside
+
+// Passed pawn bonuses and penalties per unit of rank weight.
+var (
+	bonusPasserFree       = Score{ 3, 6 } // Path to promotion is clear and safe.
+	penaltyPasserBlocked  = Score{ 2, 4 } // Stop square is occupied.
+	penaltyPasserAttacked = Score{ 1, 2 } // Path to promotion is attacked.
+	bonusUnstoppable      = Score{ 0, valueRook }
+)
+
+// Passed pawns are found by pawn structure analysis and cached, however their
+// scoring depends on the rest of the pieces so it gets evaluated every time.
+func (e *Evaluation) analyzePassers() *Evaluation {
+	white, black := e.passers(White), e.passers(Black)
+	e.score.add(white).subtract(black)
+
+	return e
+}
+
+func (e *Evaluation) passers(color uint8) (score Score) {
+	p := e.position
+	enemy := color ^ 1
+
+	// Rule of the square only applies when the opponent has no pieces left.
+	pawnEnding := p.outposts[enemy] & ^p.outposts[pawn(enemy)] & ^p.outposts[king(enemy)] == 0
+
+	outposts := e.pawns.passers[color]
+	for outposts != 0 {
+		square := outposts.pop()
+		rank := rank(color, square)
+		stop := square + eight[color]
+		path := maskInFront[color][square]
+
+		// The weight grows rapidly as the pawn advances and becomes zero
+		// for pawns that haven't left the first three ranks.
+		weight := (rank - 1) * (rank - 2)
+		if weight > 0 {
+			// In the endgame passed pawn wants the enemy king to stay away
+			// from its stop square and own king to be close to it.
+			score.endgame += weight * (distance[p.king[enemy]][stop] * 5 - distance[p.king[color]][stop] * 2)
+
+			if p.board.on(stop) {
+				score.subtract(penaltyPasserBlocked.times(weight))
+			} else if path & e.attacks[enemy] != 0 {
+				score.subtract(penaltyPasserAttacked.times(weight))
+			} else if path & p.board == 0 {
+				score.add(bonusPasserFree.times(weight))
+			}
+		}
+
+		// Unstoppable pawn: the enemy king can't catch it before promotion.
+		// Pawn on its initial rank is able to jump two squares so it is as
+		// far as the pawn on the third rank.
+		if pawnEnding && path & p.board == 0 {
+			promo := col(square) + A8 * (1 - int(color))
+			moves := min(5, A8H8 - rank)
+			chase := distance[p.king[enemy]][promo]
+			if p.color == enemy {
+				chase--
+			}
+			if moves < chase {
+				score.add(bonusUnstoppable)
+			}
+		}
+	}
+
+	return
+}
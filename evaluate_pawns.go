@@ -0,0 +1,102 @@
+package kingside
+
+// Pawn structure bonuses and penalties.
+var (
+	penaltyDoubled   = Score{ 10, 20 }
+	penaltyIsolated  = Score{ 10, 20 }
+	penaltyBackward  = Score{  8, 10 }
+	bonusConnected   = Score{  5,  5 }
+
+	// Indexed by relative rank of the pawn.
+	bonusPassed      = [8]Score{ {0, 0}, {5, 10}, {10, 15}, {15, 25}, {30, 45}, {50, 75}, {80, 120}, {0, 0} }
+	bonusCandidate   = [8]Score{ {0, 0}, {2,  5}, { 4,  8}, { 8, 12}, {15, 25}, {25, 40}, { 0,   0}, {0, 0} }
+)
+
+// Since pawn structure changes less often than piece placement the pawn scores
+// get cached in a separate table indexed by position's pawn hash.
+type PawnEntry struct {
+	id       uint64      // Pawn hash key.
+	score    Score       // Pawn structure score, positive for White.
+	passers  [2]Bitmask  // Passed pawns for both sides.
+}
+
+const pawnCacheSize = 16384 // Must be a power of two.
+
+type PawnCache [pawnCacheSize]PawnEntry
+
+// Use single statically allocated variable.
+var pawnCache PawnCache
+
+func (e *Evaluation) analyzePawns() *Evaluation {
+	key := e.position.pawnHash
+
+	// The entry with zero key is never stored which is fine since position
+	// without pawns scores zero anyway.
+	e.pawns = &pawnCache[key & (pawnCacheSize - 1)]
+	if e.pawns.id != key {
+		white, black := e.pawnStructure(White), e.pawnStructure(Black)
+		e.pawns.id = key
+		e.pawns.score = white
+		e.pawns.score.subtract(black)
+	}
+	e.score.add(e.pawns.score)
+
+	return e
+}
+
+// Evaluates pawn structure for the given side. Passed pawns get recorded in
+// the pawn cache entry so that the rest of evaluation could use them.
+func (e *Evaluation) pawnStructure(color uint8) (score Score) {
+	p := e.position
+	pawns, enemy := p.outposts[pawn(color)], p.outposts[pawn(color^1)]
+	e.pawns.passers[color] = 0
+
+	outposts := pawns
+	for outposts != 0 {
+		square := outposts.pop()
+		row, col := coordinate(square)
+		rank := rank(color, square)
+
+		// Friendly pawns on adjacent files that are level with or behind
+		// this pawn, i.e. the ones that could support its advance.
+		neighbors := maskIsolated[col] & (maskRank[row] | maskPassed[color^1][square])
+		supporters := pawns & neighbors
+
+		// Enemy pawns on adjacent files in front of the pawn.
+		sentries := enemy & maskPassed[color][square] & maskIsolated[col]
+
+		isolated := pawns & maskIsolated[col] == 0
+		doubled := pawns & maskInFront[color][square] != 0
+		exposed := enemy & maskInFront[color][square] == 0
+		passed := exposed && !doubled && sentries == 0
+
+		if doubled {
+			score.subtract(penaltyDoubled)
+		}
+
+		if isolated {
+			score.subtract(penaltyIsolated)
+		} else if supporters == 0 && maskPawn[color^1][square + eight[color]] & enemy != 0 {
+			// Backward pawn can't be supported by friendly pawns and
+			// its stop square is controlled by enemy pawns.
+			score.subtract(penaltyBackward)
+		}
+
+		// Connected pawns are either protected by a friendly pawn or
+		// stand side by side with one.
+		if maskPawn[color][square] & pawns != 0 || neighbors & maskRank[row] & pawns != 0 {
+			score.add(bonusConnected)
+		}
+
+		if passed {
+			e.pawns.passers[color] |= bit[square]
+			score.add(bonusPassed[rank])
+		} else if exposed && !doubled && supporters.count() >= sentries.count() {
+			// Candidate pawn could become passed by advancing and
+			// trading off the enemy pawns in front of it.
+			score.add(bonusCandidate[rank])
+		}
+	}
+
+	return
+}
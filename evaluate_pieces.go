@@ -0,0 +1,80 @@
+package kingside
+
+// Mobility bonus per safe square above the average for the piece kind.
+var bonusMobility = [14]Score{ Knight: {4, 4}, Bishop: {5, 5}, Rook: {2, 4}, Queen: {1, 2} }
+var averageMobility = [14]int{ Knight: 4, Bishop: 6, Rook: 7, Queen: 13 }
+
+// Piece activity bonuses.
+var (
+	bonusRookOnOpen     = Score{ 20, 10 }
+	bonusRookOnSemiOpen = Score{ 10,  5 }
+	bonusRookOn7th      = Score{ 20, 40 }
+	bonusBishopPair     = Score{ 30, 50 }
+	bonusKnightOutpost  = Score{ 15, 10 }
+)
+
+// Computes attacks for knights, bishops, rooks and queens, and evaluates their
+// mobility and activity. Kings and pawns have been set up by init().
+func (e *Evaluation) analyzePieces() *Evaluation {
+	white, black := e.pieces(White), e.pieces(Black)
+	e.score.add(white).subtract(black)
+
+	return e
+}
+
+func (e *Evaluation) pieces(color uint8) (score Score) {
+	p := e.position
+	enemy := color ^ 1
+	pawns, enemyPawns := p.outposts[pawn(color)], p.outposts[pawn(enemy)]
+
+	// Safe squares to move to are the ones that are neither occupied by
+	// friendly pieces nor attacked by enemy pawns.
+	safe := ^p.outposts[color] & ^e.attacks[pawn(enemy)]
+
+	outposts := p.outposts[color] & ^pawns & ^p.outposts[king(color)]
+	for outposts != 0 {
+		square := outposts.pop()
+		piece := p.pieces[square]
+		kind := piece.kind()
+
+		attacks := p.attacksFor(square, piece)
+		e.attacks[piece] |= attacks
+		e.attacks[color] |= attacks
+
+		mobility := (attacks & safe).count() - averageMobility[kind]
+		score.add(bonusMobility[kind].times(mobility))
+
+		switch kind {
+		case Knight:
+			// Outpost is the square on the 4th to 6th rank protected by
+			// friendly pawn that can't be attacked by enemy pawns.
+			if rank := rank(color, square); rank >= 3 && rank <= 5 {
+				if maskPawn[color][square] & pawns != 0 && maskPassed[color][square] & maskIsolated[col(square)] & enemyPawns == 0 {
+					score.add(bonusKnightOutpost)
+				}
+			}
+		case Rook:
+			if file := maskFile[col(square)]; file & pawns == 0 {
+				if file & enemyPawns == 0 {
+					score.add(bonusRookOnOpen)
+				} else {
+					score.add(bonusRookOnSemiOpen)
+				}
+			}
+			// Rook on the 7th rank is strong when it attacks enemy pawns
+			// or cuts off the enemy king on the 8th.
+			if rank(color, square) == 6 {
+				if enemyPawns & maskRank[row(square)] != 0 || rank(color, int(p.king[enemy])) == 7 {
+					score.add(bonusRookOn7th)
+				}
+			}
+		}
+	}
+
+	// Bishop pair is only a pair if the bishops control both square colors.
+	if bishops := p.outposts[bishop(color)]; bishops & maskDark != 0 && bishops & ^maskDark != 0 {
+		score.add(bonusBishopPair)
+	}
+
+	return
+}
@@ -0,0 +1,112 @@
+package kingside
+
+// Piece/square bonuses as seen from White's side, i.e. the first row of each
+// table is the 8th rank. Pawns and the king have separate midgame and endgame
+// tables; other pieces share the same table for both.
+var bonusPawn = [2][64]int{
+	{
+		  0,   0,   0,   0,   0,   0,   0,   0,
+		 50,  50,  50,  50,  50,  50,  50,  50,
+		 10,  10,  20,  30,  30,  20,  10,  10,
+		  5,   5,  10,  25,  25,  10,   5,   5,
+		  0,   0,   0,  20,  20,   0,   0,   0,
+		  5,  -5, -10,   0,   0, -10,  -5,   5,
+		  5,  10,  10, -20, -20,  10,  10,   5,
+		  0,   0,   0,   0,   0,   0,   0,   0,
+	}, {
+		  0,   0,   0,   0,   0,   0,   0,   0,
+		 80,  80,  80,  80,  80,  80,  80,  80,
+		 50,  50,  50,  50,  50,  50,  50,  50,
+		 30,  30,  30,  30,  30,  30,  30,  30,
+		 15,  15,  15,  15,  15,  15,  15,  15,
+		  5,   5,   5,   5,   5,   5,   5,   5,
+		  0,   0,   0,   0,   0,   0,   0,   0,
+		  0,   0,   0,   0,   0,   0,   0,   0,
+	},
+}
+
+var bonusKnight = [64]int{
+	-50, -40, -30, -30, -30, -30, -40, -50,
+	-40, -20,   0,   0,   0,   0, -20, -40,
+	-30,   0,  10,  15,  15,  10,   0, -30,
+	-30,   5,  15,  20,  20,  15,   5, -30,
+	-30,   0,  15,  20,  20,  15,   0, -30,
+	-30,   5,  10,  15,  15,  10,   5, -30,
+	-40, -20,   0,   5,   5,   0, -20, -40,
+	-50, -40, -30, -30, -30, -30, -40, -50,
+}
+
+var bonusBishop = [64]int{
+	-20, -10, -10, -10, -10, -10, -10, -20,
+	-10,   0,   0,   0,   0,   0,   0, -10,
+	-10,   0,   5,  10,  10,   5,   0, -10,
+	-10,   5,   5,  10,  10,   5,   5, -10,
+	-10,   0,  10,  10,  10,  10,   0, -10,
+	-10,  10,  10,  10,  10,  10,  10, -10,
+	-10,   5,   0,   0,   0,   0,   5, -10,
+	-20, -10, -10, -10, -10, -10, -10, -20,
+}
+
+var bonusRook = [64]int{
+	  0,   0,   0,   0,   0,   0,   0,   0,
+	  5,  10,  10,  10,  10,  10,  10,   5,
+	 -5,   0,   0,   0,   0,   0,   0,  -5,
+	 -5,   0,   0,   0,   0,   0,   0,  -5,
+	 -5,   0,   0,   0,   0,   0,   0,  -5,
+	 -5,   0,   0,   0,   0,   0,   0,  -5,
+	 -5,   0,   0,   0,   0,   0,   0,  -5,
+	  0,   0,   0,   5,   5,   0,   0,   0,
+}
+
+var bonusQueen = [64]int{
+	-20, -10, -10,  -5,  -5, -10, -10, -20,
+	-10,   0,   0,   0,   0,   0,   0, -10,
+	-10,   0,   5,   5,   5,   5,   0, -10,
+	 -5,   0,   5,   5,   5,   5,   0,  -5,
+	  0,   0,   5,   5,   5,   5,   0,  -5,
+	-10,   5,   5,   5,   5,   5,   0, -10,
+	-10,   0,   5,   0,   0,   0,   0, -10,
+	-20, -10, -10,  -5,  -5, -10, -10, -20,
+}
+
+var bonusKing = [2][64]int{
+	{
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-20, -30, -30, -40, -40, -30, -30, -20,
+		-10, -20, -20, -20, -20, -20, -20, -10,
+		 20,  20,   0,   0,   0,   0,  20,  20,
+		 20,  30,  10,   0,   0,  10,  30,  20,
+	}, {
+		-50, -40, -30, -20, -20, -30, -40, -50,
+		-30, -20, -10,   0,   0, -10, -20, -30,
+		-30, -10,  20,  30,  30,  20, -10, -30,
+		-30, -10,  30,  40,  40,  30, -10, -30,
+		-30, -10,  30,  40,  40,  30, -10, -30,
+		-30, -10,  20,  30,  30,  20, -10, -30,
+		-30, -30,   0,   0,   0,   0, -30, -30,
+		-50, -30, -30, -30, -30, -30, -30, -50,
+	},
+}
+
+// Piece/square table with material values included. White pieces score positive
+// and black pieces score negative so that the evaluation could simply add them
+// up for all pieces on the board.
+var pst [14][64]Score
+
+func initPST() {
+	for square := A1; square <= H8; square++ {
+		for color := uint8(White); color <= Black; color++ {
+			index, sign := flip(color, square), 1 - 2 * int(color)
+
+			pst[pawn(color)][square] = Score{ valuePawn + bonusPawn[0][index], valuePawn + bonusPawn[1][index] }.times(sign)
+			pst[knight(color)][square] = Score{ valueKnight + bonusKnight[index], valueKnight + bonusKnight[index] }.times(sign)
+			pst[bishop(color)][square] = Score{ valueBishop + bonusBishop[index], valueBishop + bonusBishop[index] }.times(sign)
+			pst[rook(color)][square] = Score{ valueRook + bonusRook[index], valueRook + bonusRook[index] }.times(sign)
+			pst[queen(color)][square] = Score{ valueQueen + bonusQueen[index], valueQueen + bonusQueen[index] }.times(sign)
+			pst[king(color)][square] = Score{ bonusKing[0][index], bonusKing[1][index] }.times(sign)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package kingside
+
+// King safety weights. All the penalties apply to midgame score only so that
+// king safety gradually fades away as the material comes off the board.
+var (
+	// Weight of each king zone square attacked by the piece kind.
+	kingAttackWeight = [14]int{ Knight: 2, Bishop: 2, Rook: 3, Queen: 5 }
+
+	// Penalty for the friendly pawn shielding the king, indexed by the number
+	// of ranks between the pawn and the king.
+	penaltyShelter   = [4]int{ 0, 0, 10, 20 }
+	penaltyNoShelter = 30
+	penaltyOpenFile  = 15
+
+	// Penalty for the enemy pawn storming the king, indexed by the number of
+	// ranks between the pawn and the king.
+	penaltyStorm     = [8]int{ 0, 0, 30, 20, 10, 0, 0, 0 }
+
+	maxKingAttack    = 400
+)
+
+func (e *Evaluation) analyzeSafety() *Evaluation {
+	white, black := e.kingSafety(White), e.kingSafety(Black)
+	e.score.add(white).subtract(black)
+
+	return e
+}
+
+func (e *Evaluation) kingSafety(color uint8) (score Score) {
+	p := e.position
+	enemy := color ^ 1
+	home := int(p.king[color])
+	kingRow, kingCol := coordinate(home)
+
+	// King zone includes the squares around the king plus one more rank
+	// facing the enemy.
+	zone := kingMoves[home] | bit[home]
+	zone |= zone.pushed(color)
+
+	// Add up weighted attacks on the king zone. Single piece kind attacking
+	// the zone is not considered dangerous unless it's the queen.
+	units, attackers := 0, 0
+	for kind := Knight; kind <= Queen; kind += 2 {
+		if hits := e.attacks[Piece(kind) | Piece(enemy)] & zone; hits != 0 {
+			units += kingAttackWeight[kind] * hits.count()
+			attackers++
+		}
+	}
+	if attackers > 1 || (attackers == 1 && e.attacks[queen(enemy)] & zone != 0) {
+		score.midgame -= min(units * units / 2, maxKingAttack)
+	}
+
+	// Pawn shield and pawn storm on the king's file and adjacent files.
+	for file := max(kingCol - 1, 0); file <= min(kingCol + 1, 7); file++ {
+		front := (maskInFront[color][square(kingRow, file)] | bit[square(kingRow, file)]) & maskFile[file]
+
+		if shelter := p.outposts[pawn(color)] & front; shelter != 0 {
+			score.midgame -= penaltyShelter[min(abs(row(shelter.closest(color)) - kingRow), 3)]
+		} else {
+			score.midgame -= penaltyNoShelter
+			if p.outposts[pawn(enemy)] & maskFile[file] == 0 {
+				score.midgame -= penaltyOpenFile
+			}
+		}
+
+		if storm := p.outposts[pawn(enemy)] & front; storm != 0 {
+			score.midgame -= penaltyStorm[abs(row(storm.closest(color)) - kingRow)]
+		}
+	}
+
+	return
+}
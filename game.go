@@ -5,8 +5,16 @@ import (
 	`time`
 )
 
+// Initial position in FEN format.
+const initialFEN = `rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1`
+
 type Game struct {
-	initial     string  // Initial position (FEN or algebraic).
+	initial     string    // Initial position (FEN or algebraic).
+	pv          []Move    // Principal variation found by the last search.
+	score       int       // Score of the best move found by the last search.
+	depth       int       // Depth of the last completed search iteration, 0 for book move.
+	moves       []Move    // Moves made in the game so far.
+	comments    []string  // Search results for the moves made by the engine.
 }
 
 // Use single statically allocated variable.
@@ -21,7 +29,7 @@ func NewGame(args ...string) *Game {
 	game = Game{}
 	switch len(args) {
 	case 0: // Initial position.
-		game.initial = `rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1`
+		game.initial = initialFEN
 	case 1: // Genuine FEN.
 		game.initial = args[0]
 	case 2: // Donna chess format (white and black).
@@ -30,9 +38,10 @@ func NewGame(args ...string) *Game {
 	return &game
 }
 
-func (game *Game) start() *Position {
-	engine.clock.halt = false
+func (game *Game) start() (*Position, error) {
+	engine.clock.resume()
 	tree, node, rootNode = [1024]Position{}, 0, 0
+	game.moves, game.comments = nil, nil
 
 	// Was the game started with FEN or algebraic notation?
 	sides := strings.Split(game.initial, ` : `)
@@ -49,20 +58,131 @@ func (game *Game) position() *Position {
 // "The question of whether machines can think is about as relevant as the
 // question of whether submarines can swim." -- Edsger W. Dijkstra
 func (game *Game) Think() Move {
-	start := time.Now()
+	engine.startClock()
+	return game.deepening()
+}
+
+// Iterative deepening driver that expects the clock to be started by the caller.
+// This lets UCI start the clock synchronously and then run the search in its own
+// goroutine while still reading the commands.
+func (game *Game) deepening() Move {
 	position := game.position()
 	rootNode = node
 
-	_, move := position.search(-Checkmate, Checkmate, 1)
-	game.printBestMove(move, since(start))
-	return move
+	defer engine.stopClock()
+	cache.newSearch()
+	stats.reset()
+
+	// Play the book move right away if we've got one.
+	game.score, game.depth = 0, 0
+	if move := game.bookMove(position); move != Move(0) {
+		game.pv = []Move{ move }
+		game.printBestMove(move, since(engine.clock.start))
+		return move
+	}
+
+	maxDepth := engine.options.maxDepth
+	if maxDepth <= 0 || maxDepth > MaxDepth {
+		maxDepth = MaxDepth
+	}
+
+	// Iterative deepening: search one ply deeper on each iteration reusing
+	// root moves rearranged by the scores from the previous iteration.
+	bestMove := Move(0)
+	game.pv = nil
+	for depth := 1; depth <= maxDepth; depth++ {
+		score, move := position.search(-Checkmate, Checkmate, depth)
+
+		// Discard results of interrupted iteration unless we haven't
+		// got any move at all.
+		if engine.clock.halted() {
+			if bestMove == Move(0) {
+				bestMove, game.pv = move, []Move{ move }
+			}
+			break
+		}
+		if move == Move(0) {
+			break // Checkmate or stalemate.
+		}
+		if depth > 1 {
+			engine.volatility(move != bestMove)
+		}
+		bestMove, game.pv, game.score, game.depth = move, rootPrincipal(), score, depth
+		game.printPrincipal(depth, score, game.pv)
+
+		// No reason to search any deeper once we've found a forced mate
+		// or when we're about to run out of time.
+		if abs(score) >= Checkmate - MaxPly || engine.softLimit() {
+			break
+		}
+	}
+
+	// Infinite search and pondering never report the best move until
+	// explicitly stopped.
+	for (engine.options.infinite || engine.options.ponder) && !engine.clock.halted() {
+		time.Sleep(time.Millisecond * Ping)
+	}
+
+	game.printBestMove(bestMove, since(engine.clock.start))
+	return bestMove
+}
+
+// Returns a move picked from the opening book, or Move(0) if the book is not in
+// use or has no valid moves for the position. The book is ignored when running
+// infinite search or pondering.
+func (game *Game) bookMove(p *Position) Move {
+	if !engine.ownBook || engine.book == nil || engine.options.infinite || engine.options.ponder {
+		return Move(0)
+	}
+
+	if move := engine.book.pickMove(p); move != Move(0) {
+		if NewGen(p, MaxPly).generateAllMoves().validOnly().amongValid(move) {
+			return move
+		}
+	}
+	return Move(0)
+}
+
+// Adds the move made in the game along with optional comment.
+func (game *Game) record(move Move, comment string) *Game {
+	game.moves = append(game.moves, move)
+	game.comments = append(game.comments, comment)
+	return game
+}
+
+// Takes back the last recorded move.
+func (game *Game) undo() *Game {
+	if size := len(game.moves); size > 0 {
+		game.moves, game.comments = game.moves[:size-1], game.comments[:size-1]
+	}
+	return game
+}
+
+// Returns the result of the last search as move comment, ex. "+0.25/12".
+func (game *Game) analysis() string {
+	if game.depth == 0 {
+		return `book`
+	}
+	return analysis(game.score, game.depth)
+}
+
+func (game *Game) printPrincipal(depth, score int, pv []Move) {
+	if engine.quiet {
+		return
+	}
+	if engine.uci {
+		engine.uciScore(depth, score, -Checkmate, Checkmate, pv)
+	}
 }
 
 func (game *Game) printBestMove(move Move, duration int64) {
+	if engine.quiet {
+		return
+	}
 	if engine.uci {
 		engine.uciBestMove(move, duration)
 	} else {
-		engine.replBestMove(move)
+		engine.replBestMove(move, game.pv)
 	}
 }
 
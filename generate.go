@@ -1,11 +1,17 @@
 package kingside
 
 import (
+	`sort`
 )
 
+type MoveWithScore struct {
+	move     Move
+	score    int
+}
+
 type MoveGen struct {
 	p        *Position
-	list     [128]Move
+	list     [128]MoveWithScore
 	ply      int
 	head     int
 	tail     int
@@ -23,7 +29,7 @@ var moveList [MaxPly+1]MoveGen
 func NewGen(p *Position, ply int) (gen *MoveGen) {
 	gen = &moveList[ply]
 	gen.p = p
-	gen.list = [128]Move{}
+	gen.list = [128]MoveWithScore{}
 	gen.ply = ply
 	gen.head, gen.tail = 0, 0
 	gen.pins = p.pinnedMask(p.king[p.color])
@@ -60,7 +66,7 @@ func (gen *MoveGen) onlyMove() bool {
 
 func (gen *MoveGen) NextMove() (move Move) {
 	if gen.head < gen.tail {
-		move = gen.list[gen.head]
+		move = gen.list[gen.head].move
 		gen.head++
 	}
 	return
@@ -106,7 +112,7 @@ func (gen *MoveGen) amongValid(someMove Move) bool {
 }
 
 func (gen *MoveGen) add(move Move) *MoveGen {
-	gen.list[gen.tail] = move
+	gen.list[gen.tail] = MoveWithScore{move, 0}
 	gen.tail++
 	return gen
 }
@@ -120,6 +126,45 @@ func (gen *MoveGen) remove() *MoveGen {
 	return gen
 }
 
+// Moves given move to the head of the list so that it gets returned first by
+// NextMove(). The move is ignored if it hasn't been generated.
+func (gen *MoveGen) prioritize(someMove Move) *MoveGen {
+	if someMove != Move(0) {
+		for i := gen.head; i < gen.tail; i++ {
+			if gen.list[i].move == someMove {
+				entry := gen.list[i]
+				copy(gen.list[gen.head+1:i+1], gen.list[gen.head:i])
+				gen.list[gen.head] = entry
+				break
+			}
+		}
+	}
+	return gen
+}
+
+// Orders captures and promotions using MVV/LVA heuristic, i.e. most valuable
+// victim first, and among the same victims the least valuable attacker first.
+func (gen *MoveGen) rankCaptures() *MoveGen {
+	for i := gen.head; i < gen.tail; i++ {
+		move := gen.list[i].move
+		gen.list[i].score = (move.capture().kind() + move.promo().kind()) * 16 - move.piece().kind()
+	}
+	return gen.sort()
+}
+
+// Assigns the score to the move most recently returned by NextMove().
+func (gen *MoveGen) setScore(score int) *MoveGen {
+	gen.list[gen.head-1].score = score
+	return gen
+}
+
+// Sorts generated moves by their scores in descending order. The sort is stable
+// so that the moves with equal scores preserve their original order.
+func (gen *MoveGen) sort() *MoveGen {
+	sort.Stable(byScore{gen.list[:gen.tail]})
+	return gen.reset()
+}
+
 // Returns an array of generated moves by continuously appending the NextMove()
 // until the list is empty.
 func (gen *MoveGen) allMoves() (moves []Move) {
@@ -130,3 +175,11 @@ func (gen *MoveGen) allMoves() (moves []Move) {
 
 	return
 }
+
+type byScore struct {
+	list []MoveWithScore
+}
+
+func (her byScore) Len() int           { return len(her.list) }
+func (her byScore) Swap(i, j int)      { her.list[i], her.list[j] = her.list[j], her.list[i] }
+func (her byScore) Less(i, j int) bool { return her.list[i].score > her.list[j].score }
@@ -43,11 +43,7 @@ func (gen *MoveGen) generateEvasions() *MoveGen {
 	// the attacking piece?
 	pawns := maskPawn[color][attackSquare] & p.outposts[pawn(color)]
 	for pawns != 0 {
-		move := NewMove(p, pawns.pop(), attackSquare)
-		if attackSquare >= A8 || attackSquare <= H1 {
-			move = move.promote(Queen)
-		}
-		gen.add(move)
+		gen.movePawn(pawns.pop(), bit[attackSquare])
 	}
 
 	// Rare case when the check could be avoided by en-passant capture.
@@ -76,15 +72,11 @@ func (gen *MoveGen) generateEvasions() *MoveGen {
 	}
 	pawns &= block; jumps &= block
 
-	// Handle one-square pawn pushes: promote to Queen if reached last rank.
+	// Handle one-square pawn pushes including all promotions if the pawn
+	// reached last rank.
 	for pawns != 0 {
 		to := pawns.pop()
-		from := to - eight[color]
-		move := NewMove(p, from, to) // Can't cause en-passant.
-		if to >= A8 || to <= H1 {
-			move = move.promote(Queen)
-		}
-		gen.add(move)
+		gen.movePawn(to - eight[color], bit[to])
 	}
 
 	// Handle two-square pawn jumps that can cause en-passant.
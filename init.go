@@ -52,6 +52,7 @@ var (
 func init() {
 	initMasks()
 	initArrays()
+	initPST()
 }
 
 func initMasks() {
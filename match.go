@@ -0,0 +1,276 @@
+package kingside
+
+import (
+	`fmt`
+	`math`
+	`os`
+)
+
+// Self-play match between two engine configurations. Each opening is played
+// twice with colors reversed.
+type Match struct {
+	engines   [2]Engine   // Engine configurations playing each other.
+	caches    [2]Cache    // Each engine keeps its own transposition table.
+	openings  []*Epd      // Starting positions.
+	client    *Client     // External engine playing instead of the second one.
+	output    string      // PGN file to save the games to.
+	wins      int         // Games won by the first engine.
+	draws     int         // Drawn games.
+	losses    int         // Games lost by the first engine.
+}
+
+// Creates the match using openings from the EPD file. The engines are passed by
+// value since NewEngine() always returns pointer to the same statically allocated
+// engine.
+func NewMatch(fileName string, first, second Engine) (*Match, error) {
+	openings, err := NewEpdFromFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	if len(openings) == 0 {
+		return nil, fmt.Errorf("%s: no openings", fileName)
+	}
+
+	match := &Match{ openings: openings }
+	match.engines[0], match.engines[1] = first, second
+	for i := range match.engines {
+		match.engines[i].uci, match.engines[i].quiet = false, true
+		match.caches[i] = *NewCache(match.engines[i].cacheSize)
+	}
+
+	return match, nil
+}
+
+// Replaces the second engine with external UCI engine to run a gauntlet rather
+// than self-play.
+func (m *Match) Versus(client *Client) *Match {
+	m.client = client
+	return m
+}
+
+// Saves the games to PGN file as they get played.
+func (m *Match) Save(fileName string) *Match {
+	m.output = fileName
+	return m
+}
+
+// Plays all the games and reports the results as they come in.
+func (m *Match) Play() *Match {
+	saved := engine
+	defer func() { engine = saved }()
+
+	for i, opening := range m.openings {
+		if err := m.pair(i, opening); err != nil {
+			engine.reply("%3d) %s: %s\n", i + 1, opening.fen, err)
+		}
+	}
+	engine.reply("\n%s\n", m)
+
+	return m
+}
+
+// Plays two games from the opening with colors reversed and reports results.
+func (m *Match) pair(i int, opening *Epd) error {
+	names := [2]string{ `first`, `second` }
+	if m.client != nil {
+		names[1] = m.client.name
+	}
+
+	for white := 0; white < 2; white++ {
+		status, reason, err := m.play(opening, white)
+		if err != nil {
+			return err
+		}
+
+		result := m.record(status, white)
+		if m.output != `` {
+			m.save(names[white], names[white^1], result, i + 1)
+		}
+		engine.reply("%3d) %s vs %s %-7s %-21s %s\n", i + 1, names[white], names[white^1], result, reason, m)
+	}
+	return nil
+}
+
+// Plays single game from the opening, the white index tells which engine gets
+// white pieces. Returns final game status and how the game has ended. External
+// engine that fails to respond or makes invalid move forfeits the game.
+func (m *Match) play(opening *Epd, white int) (int, string, error) {
+	game := NewGame(opening.fen)
+	position, err := game.start()
+	if err != nil {
+		return InProgress, ``, err
+	}
+
+	// Game won by our engine when external one forfeits.
+	forfeit := func(err error) (int, string, error) {
+		if white == 1 {
+			return BlackWon, err.Error(), nil
+		}
+		return WhiteWon, err.Error(), nil
+	}
+	if m.client != nil {
+		if err = m.client.newGame(); err != nil {
+			return forfeit(err)
+		}
+	}
+
+	moves := []Move{}
+	for {
+		move, comment, side := Move(0), ``, white ^ int(position.color)
+		if side == 1 && m.client != nil {
+			// Don't ask external engine to move in checkmate or stalemate.
+			if NewGen(position, MaxPly).generateAllMoves().anyValid() {
+				if move, err = m.client.bestMove(position, opening.fen, moves); err != nil {
+					return forfeit(err)
+				}
+				game.score = m.client.info.score
+				comment = analysis(game.score, m.client.info.depth)
+			}
+		} else {
+			// Switch to the engine that is on move along with its cache.
+			engine, cache = m.engines[side], m.caches[side]
+			move = game.Think()
+			m.caches[side] = cache
+			comment = game.analysis()
+		}
+
+		// No valid moves: the opening position is either checkmate or
+		// stalemate.
+		if move == Move(0) {
+			if !position.isInCheck(position.color) {
+				return Stalemate, gameOver(Stalemate), nil
+			}
+			if position.color == White {
+				return BlackWon, gameOver(BlackWon), nil
+			}
+			return WhiteWon, gameOver(WhiteWon), nil
+		}
+
+		// The status gets checked after the move made from the root. Draw
+		// rules are only checked for zero score, so make sure we don't miss
+		// them when external engine reports non-zero score for the draw.
+		// Checkmate and stalemate get caught when looking for the next move.
+		rootNode = node
+		status := position.status(move, game.score)
+		if status == InProgress && game.score != 0 {
+			if draw := position.status(move, 0); draw != InProgress && draw != Stalemate {
+				status = draw
+			}
+		}
+		position, moves = position.makeMove(move), append(moves, move)
+		game.record(move, comment)
+		if status != InProgress {
+			return status, gameOver(status), nil
+		}
+
+		// Make sure we don't run out of the move tree in endless game.
+		if node >= len(tree) - MaxPly - 1 {
+			return FiftyMoves, gameOver(FiftyMoves), nil
+		}
+	}
+}
+
+// Appends the game that has just been played to the PGN file.
+func (m *Match) save(white, black, result string, round int) {
+	tags := map[string]string{
+		`Event`:  `kingside match`,
+		`Round`:  fmt.Sprintf("%d", round),
+		`White`:  white,
+		`Black`:  black,
+		`Result`: result,
+	}
+	pgn := game.pgn(game.position(), tags)
+
+	file, err := os.OpenFile(m.output, os.O_APPEND | os.O_CREATE | os.O_WRONLY, 0644)
+	if err == nil {
+		_, err = file.WriteString(pgn)
+		file.Close()
+	}
+	if err != nil {
+		engine.reply("%s\n", err)
+	}
+}
+
+// Updates the score with the game status and returns game result in PGN format.
+func (m *Match) record(status, white int) string {
+	switch status {
+	case WhiteWon, WhiteWinning:
+		if white == 0 {
+			m.wins++
+		} else {
+			m.losses++
+		}
+		return `1-0`
+	case BlackWon, BlackWinning:
+		if white == 0 {
+			m.losses++
+		} else {
+			m.wins++
+		}
+		return `0-1`
+	}
+	m.draws++
+	return `1/2-1/2`
+}
+
+// Returns the first engine's score as a fraction of the games played.
+func (m *Match) score() float64 {
+	games := m.wins + m.draws + m.losses
+	if games == 0 {
+		return 0.5
+	}
+	return (float64(m.wins) + float64(m.draws) / 2.0) / float64(games)
+}
+
+// Returns Elo difference between the first and the second engine along with the
+// margin of error for 95% confidence. The score gets clamped so that all wins or
+// all losses don't result in infinite rating difference. The margin is computed
+// with one extra win and one extra loss (Agresti-Coull style) so that it stays
+// non-zero when all the games end the same way.
+func (m *Match) elo() (elo, margin float64) {
+	if m.wins + m.draws + m.losses == 0 {
+		return
+	}
+
+	wins, draws, losses := float64(m.wins + 1), float64(m.draws), float64(m.losses + 1)
+	games := wins + draws + losses
+	score := (wins + draws / 2.0) / games
+	variance := (wins * math.Pow(1.0 - score, 2) +
+		draws * math.Pow(0.5 - score, 2) +
+		losses * math.Pow(score, 2)) / games
+	deviation := math.Sqrt(variance / games)
+
+	low, high := eloDifference(score - 1.96 * deviation), eloDifference(score + 1.96 * deviation)
+	return eloDifference(m.score()), (high - low) / 2.0
+}
+
+func (m *Match) String() string {
+	elo, margin := m.elo()
+	return fmt.Sprintf("W/D/L %d/%d/%d, score %.1f%%, elo %+.1f +/- %.1f",
+		m.wins, m.draws, m.losses, m.score() * 100.0, elo, margin)
+}
+
+// Converts score fraction to Elo rating difference.
+func eloDifference(score float64) float64 {
+	score = math.Max(0.001, math.Min(0.999, score))
+	return -400.0 * math.Log10(1.0 / score - 1.0)
+}
+
+// Describes how the game with given status has ended.
+func gameOver(status int) string {
+	switch status {
+	case WhiteWon, BlackWon:
+		return `checkmate`
+	case WhiteWinning, BlackWinning:
+		return `forced mate`
+	case Stalemate:
+		return `stalemate`
+	case Insufficient:
+		return `insufficient material`
+	case Repetition:
+		return `repetition`
+	case FiftyMoves:
+		return `fifty moves`
+	}
+	return ``
+}
@@ -0,0 +1,23 @@
+package kingside
+
+import `testing`
+
+func TestMatchElo(t *testing.T) {
+	tests := []struct{ wins, draws, losses int; low, high float64 }{
+		{ 50, 0, 50, -1.0, 1.0 },
+		{ 60, 20, 20, 100.0, 200.0 },
+		{ 0, 0, 5, -1200.0, -1100.0 },
+		{ 5, 0, 0, 1100.0, 1200.0 },
+		{ 0, 10, 0, -1.0, 1.0 },
+	}
+	for _, test := range tests {
+		m := &Match{ wins: test.wins, draws: test.draws, losses: test.losses }
+		elo, margin := m.elo()
+		if elo < test.low || elo > test.high {
+			t.Errorf("W/D/L %d/%d/%d: expected elo in [%.1f, %.1f], got %.1f", test.wins, test.draws, test.losses, test.low, test.high, elo)
+		}
+		if margin <= 0.0 || margin >= 1200.0 {
+			t.Errorf("W/D/L %d/%d/%d: unexpected margin %.1f", test.wins, test.draws, test.losses, margin)
+		}
+	}
+}
@@ -101,7 +101,7 @@ func NewMoveFromString(p *Position, e2e4 string) (move Move, validMoves []Move)
 
 	// Before returning the move make sure it is valid in current position.
 	defer func() {
-		gen := NewMoveGen(p).generateAllMoves().validOnly()
+		gen := NewGen(p, MaxPly).generateAllMoves().validOnly()
 		validMoves = gen.allMoves()
 		if move != Move(0) && !gen.amongValid(move) {
 			move = Move(0)
@@ -150,6 +150,9 @@ func NewMoveFromString(p *Position, e2e4 string) (move Move, validMoves []Move)
 			return
 		}
 	}
+
+	// Last resort: standard algebraic notation, ex. `Nf3`, `exd5`, or `O-O`.
+	move, _ = NewMoveFromSan(p, e2e4)
 	return
 }
 
@@ -0,0 +1,102 @@
+package kingside
+
+import `time`
+
+// Standard perft positions with known node counts used to verify move generator.
+var perftSuite = []struct {
+	fen    string
+	depth  int
+	nodes  int64
+}{
+	{ `rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1`, 5, 4865609 },
+	{ `r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1`, 4, 4085603 },
+	{ `8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1`, 5, 674624 },
+	{ `r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1`, 4, 422333 },
+	{ `rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8`, 4, 2103487 },
+	{ `r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10`, 4, 3894594 },
+	{ `3k4/3p4/8/K1P4r/8/8/8/8 b - - 0 1`, 6, 1134888 },        // Illegal en-passant (pinned).
+	{ `8/8/4k3/8/2p5/8/B2P2K1/8 w - - 0 1`, 6, 1015133 },       // Illegal en-passant (exposes check).
+	{ `8/8/1k6/2b5/2pP4/8/5K2/8 b - d3 0 1`, 6, 1440467 },      // En-passant capture checks opponent.
+	{ `5k2/8/8/8/8/8/8/4K2R w K - 0 1`, 6, 661072 },            // Short castle gives check.
+	{ `3k4/8/8/8/8/8/8/R3K3 w Q - 0 1`, 6, 803711 },            // Long castle gives check.
+	{ `r3k2r/1b4bq/8/8/8/8/7B/R3K2R w KQkq - 0 1`, 4, 1274206 }, // Castle rights.
+	{ `r3k2r/8/3Q4/8/8/5q2/8/R3K2R b KQkq - 0 1`, 4, 1720476 },  // Castling prevented.
+	{ `2K2r2/4P3/8/8/8/8/8/3k4 w - - 0 1`, 6, 3821001 },         // Promote out of check.
+	{ `8/8/1P2K3/8/2n5/1q6/8/5k2 b - - 0 1`, 5, 1004658 },       // Discovered check.
+	{ `4k3/1P6/8/8/8/8/K7/8 w - - 0 1`, 6, 217342 },             // Promote to give check.
+	{ `8/P1k5/K7/8/8/8/8/8 w - - 0 1`, 6, 92683 },               // Underpromote to check.
+	{ `K1k5/8/P7/8/8/8/8/8 w - - 0 1`, 6, 2217 },                // Self stalemate.
+	{ `8/k1P5/8/1K6/8/8/8/8 w - - 0 1`, 7, 567584 },             // Stalemate and checkmate.
+	{ `8/8/2k5/5q2/5n2/8/5K2/8 b - - 0 1`, 4, 23527 },           // Stalemate and checkmate.
+}
+
+// Counts leaf nodes of the legal move tree of given depth.
+func (p *Position) perft(depth int) (total int64) {
+	if depth == 0 {
+		return 1
+	}
+
+	gen := NewMoveGen(p).generateAllMoves()
+	for move := gen.NextMove(); move != 0; move = gen.NextMove() {
+		if !gen.isValid(move) {
+			continue
+		}
+		if depth == 1 {
+			total++ // No need to make the move to count it.
+			continue
+		}
+		position := p.makeMove(move)
+		total += position.perft(depth - 1)
+		position.undoLastMove()
+	}
+	return
+}
+
+// Runs perft and prints node counts for each root move followed by the total.
+func (p *Position) divide(depth int) (total int64) {
+	start := time.Now()
+	rootNode = node
+
+	gen := NewGen(p, 0).generateAllMoves().validOnly()
+	for move := gen.NextMove(); move != 0; move = gen.NextMove() {
+		position := p.makeMove(move)
+		count := position.perft(depth - 1)
+		position.undoLastMove()
+		total += count
+
+		if engine.uci {
+			engine.reply("%s: %d\n", move.notation(), count)
+		} else {
+			engine.reply("%s: %d\n", move, count)
+		}
+	}
+
+	duration := since(start)
+	engine.reply("\nnodes %d time %d nps %d\n", total, duration, total * 1000 / max64(duration, 1))
+	return
+}
+
+// Runs perft for all positions of the suite and reports the ones that don't
+// match expected node counts. Returns true if all of them match.
+func PerftSuite() bool {
+	passed := 0
+	for _, test := range perftSuite {
+		game := NewGame(test.fen)
+		position, err := game.start()
+		if err != nil {
+			engine.reply("%s: %s\n", test.fen, err)
+			continue
+		}
+
+		rootNode = node
+		nodes := position.perft(test.depth)
+		if nodes == test.nodes {
+			passed++
+		} else {
+			engine.reply("%s: depth %d expected %d got %d\n", test.fen, test.depth, test.nodes, nodes)
+		}
+	}
+
+	engine.reply("perft suite: %d of %d passed\n", passed, len(perftSuite))
+	return passed == len(perftSuite)
+}
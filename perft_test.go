@@ -0,0 +1,28 @@
+package kingside
+
+import `testing`
+
+// Move generator must match known node counts for all the positions of the suite.
+func TestPerftSuite(t *testing.T) {
+	for _, test := range perftSuite {
+		position, err := NewGame(test.fen).start()
+		if err != nil {
+			t.Errorf("%s: %s", test.fen, err)
+			continue
+		}
+
+		rootNode = node
+		if nodes := position.perft(test.depth); nodes != test.nodes {
+			t.Errorf("%s: depth %d expected %d got %d", test.fen, test.depth, test.nodes, nodes)
+		}
+	}
+}
+
+// Evasions must include underpromotions.
+func TestPerftPromoteOutOfCheck(t *testing.T) {
+	position, _ := NewGame(`2K2r2/4P3/8/8/8/8/8/3k4 w - - 0 1`).start()
+	rootNode = node
+	if nodes := position.perft(1); nodes != 11 {
+		t.Errorf("expected 11 got %d", nodes)
+	}
+}
@@ -0,0 +1,327 @@
+package kingside
+
+import (
+	`bytes`
+	`fmt`
+	`io/ioutil`
+	`sort`
+	`strings`
+	`time`
+)
+
+// Game record in Portable Game Notation. Only the main line gets replayed and
+// kept while variations, comments and annotations are skipped.
+type PgnGame struct {
+	tags     map[string]string  // Tag pairs, ex. "Event" or "FEN".
+	moves    []Move             // Main line moves.
+	result   string             // Game termination marker: 1-0, 0-1, 1/2-1/2, or *.
+}
+
+// PGN tokenizer that skips whitespace, comments, and escaped lines.
+type pgnScanner struct {
+	text     string
+	offset   int
+	line     int
+}
+
+// Reads all the games from PGN file.
+func NewPgnFromFile(fileName string) ([]*PgnGame, error) {
+	text, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	return NewPgn(string(text))
+}
+
+// Parses PGN text and replays main line moves of each game. Malformed or invalid
+// moves are reported as errors along with the game and move number.
+func NewPgn(text string) (games []*PgnGame, err error) {
+	scanner := &pgnScanner{ text: text, line: 1 }
+
+	var pgn *PgnGame
+	var position *Position
+	depth := 0 // Variation nesting level.
+
+	// Adds the game we've been parsing to the list.
+	finish := func() {
+		if pgn != nil {
+			if pgn.result == `` {
+				pgn.result = pgn.tags[`Result`]
+			}
+			games = append(games, pgn)
+		}
+		pgn, position, depth = nil, nil, 0
+	}
+
+	for token := scanner.next(); token != ``; token = scanner.next() {
+		if pgn == nil {
+			pgn = &PgnGame{ tags: map[string]string{} }
+		}
+		where := fmt.Sprintf("game %d, line %d", len(games) + 1, scanner.line)
+
+		switch {
+		case token == `[`:
+			// New tag section after the movetext means the previous game
+			// had no termination marker.
+			if position != nil {
+				finish()
+				pgn = &PgnGame{ tags: map[string]string{} }
+			}
+			name, value, closing := scanner.next(), scanner.next(), scanner.next()
+			if name == `` || value == `` || value[0] != '"' || closing != `]` {
+				return games, fmt.Errorf("%s: invalid tag pair", where)
+			}
+			pgn.tags[name] = value[1:]
+
+		case token == `(`:
+			depth++
+
+		case token == `)`:
+			if depth--; depth < 0 {
+				return games, fmt.Errorf("%s: unexpected ')'", where)
+			}
+
+		case token == `1-0` || token == `0-1` || token == `1/2-1/2` || token == `*`:
+			if depth == 0 {
+				pgn.result = token
+				finish()
+			}
+
+		case depth > 0 || token[0] == '$' || token[0] == '"' || token == `]`:
+			// Skip variations, NAGs, and stray tokens.
+
+		default:
+			// Move number indication is optional, ex. "12.", "12...", or "12.Nf3".
+			san := strings.TrimLeft(token, `0123456789`)
+			if len(san) < len(token) && (san == `` || san[0] == '.') {
+				san = strings.TrimLeft(san, `.`)
+			} else {
+				san = token
+			}
+			if san == `` {
+				continue
+			}
+
+			if position == nil {
+				if position, err = pgn.game().start(); err != nil {
+					return games, fmt.Errorf("%s: %s", where, err)
+				}
+			}
+
+			move, err := NewMoveFromSan(position, san)
+			if err != nil {
+				number := fmt.Sprintf("%d.", position.fullmove)
+				if position.color == Black {
+					number += `..`
+				}
+				return games, fmt.Errorf("%s, move %s%s: %s", where, number, san, err)
+			}
+			pgn.moves = append(pgn.moves, move)
+			position = position.makeMove(move)
+		}
+	}
+	if pgn != nil && (len(pgn.tags) > 0 || len(pgn.moves) > 0) {
+		finish()
+	}
+
+	return games, nil
+}
+
+// Creates new game from the initial position of the PGN game.
+func (pgn *PgnGame) game() *Game {
+	if fen, ok := pgn.tags[`FEN`]; ok {
+		return NewGame(fen)
+	}
+	return NewGame()
+}
+
+// Starts the game and replays all its moves returning final position.
+func (pgn *PgnGame) replay() (*Game, *Position, error) {
+	game := pgn.game()
+	position, err := game.start()
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, move := range pgn.moves {
+		position = position.makeMove(move)
+		game.record(move, ``)
+	}
+	return game, position, nil
+}
+
+// Returns the game in PGN format. The position is expected to be the final one,
+// and the tags supplement or override the Seven Tag Roster.
+func (game *Game) pgn(p *Position, tags map[string]string) string {
+	roster := []string{ `Event`, `Site`, `Date`, `Round`, `White`, `Black`, `Result` }
+	values := map[string]string{
+		`Event`:  `?`,
+		`Site`:   `?`,
+		`Date`:   time.Now().Format(`2006.01.02`),
+		`Round`:  `-`,
+		`White`:  `?`,
+		`Black`:  `?`,
+		`Result`: game.result(p),
+	}
+
+	// Positions of the game are kept in the move tree: the final position is at
+	// the current node, and the ones before each move precede it.
+	first := node - len(game.moves)
+	if fen := tree[first].fen(); fen != initialFEN {
+		values[`SetUp`], values[`FEN`] = `1`, fen
+	}
+
+	extra := []string{}
+	for name, value := range tags {
+		if _, ok := values[name]; !ok {
+			extra = append(extra, name)
+		}
+		values[name] = value
+	}
+	sort.Strings(extra)
+	if _, ok := values[`FEN`]; ok {
+		extra = append([]string{ `SetUp`, `FEN` }, extra...)
+	}
+
+	var buffer bytes.Buffer
+	for _, name := range append(roster, extra...) {
+		value := strings.Replace(strings.Replace(values[name], `\`, `\\`, -1), `"`, `\"`, -1)
+		fmt.Fprintf(&buffer, "[%s \"%s\"]\n", name, value)
+	}
+	buffer.WriteString("\n")
+
+	// Movetext: black move after the comment needs its own move number.
+	tokens := []string{}
+	for i, move := range game.moves {
+		position := &tree[first + i]
+		if position.color == White {
+			tokens = append(tokens, fmt.Sprintf("%d.", position.fullmove))
+		} else if i == 0 || game.comments[i-1] != `` {
+			tokens = append(tokens, fmt.Sprintf("%d...", position.fullmove))
+		}
+		tokens = append(tokens, position.san(move))
+		if comment := game.comments[i]; comment != `` {
+			tokens = append(tokens, `{` + comment + `}`)
+		}
+	}
+	tokens = append(tokens, values[`Result`])
+
+	// Keep the lines under 80 characters.
+	width := 0
+	for i, token := range tokens {
+		if i > 0 && width + len(token) >= 80 {
+			buffer.WriteString("\n")
+			width = 0
+		} else if i > 0 {
+			buffer.WriteString(` `)
+			width++
+		}
+		buffer.WriteString(token)
+		width += len(token)
+	}
+	buffer.WriteString("\n\n")
+
+	return buffer.String()
+}
+
+// Returns PGN result for the final position of the game as reported by status().
+func (game *Game) result(p *Position) string {
+	if len(game.moves) == 0 {
+		return `*`
+	}
+
+	score := 0
+	if p.isInCheck(p.color) && !NewGen(p, MaxPly).generateAllMoves().anyValid() {
+		score = Checkmate - 1
+	}
+
+	// The status gets checked after the last move made from the root.
+	last, root := game.moves[len(game.moves)-1], rootNode
+	p = p.undoLastMove()
+	rootNode = node
+	status := p.status(last, score)
+	p.makeMove(last)
+	rootNode = root
+
+	switch status {
+	case InProgress, WhiteWinning, BlackWinning:
+		return `*`
+	case WhiteWon:
+		return `1-0`
+	case BlackWon:
+		return `0-1`
+	}
+	return `1/2-1/2`
+}
+
+// Formats search score and depth as move comment, ex. "+0.25/12" or "-M3/20"
+// when getting mated in three moves.
+func analysis(score, depth int) string {
+	if abs(score) >= Checkmate - MaxPly {
+		sign, mate := `+`, (Checkmate - score + 1) / 2
+		if score < 0 {
+			sign, mate = `-`, (Checkmate + score + 1) / 2
+		}
+		return fmt.Sprintf("%sM%d/%d", sign, mate, depth)
+	}
+	return fmt.Sprintf("%+.2f/%d", float64(score) / float64(onePawn), depth)
+}
+
+// Returns next token: tag and variation brackets, string literals with leading
+// double quote, or symbols like moves, move numbers, NAGs, and results. Empty
+// string means we've reached the end of the text.
+func (s *pgnScanner) next() string {
+	for s.offset < len(s.text) {
+		char := s.text[s.offset]
+		switch {
+		case char == '\n':
+			s.line++
+			s.offset++
+			// Lines starting with '%' are escaped.
+			if s.offset < len(s.text) && s.text[s.offset] == '%' {
+				s.skipUntil('\n')
+			}
+		case char == ' ' || char == '\t' || char == '\r':
+			s.offset++
+		case char == '{':
+			s.skipUntil('}')
+			s.offset++
+		case char == ';':
+			s.skipUntil('\n')
+		case char == '[' || char == ']' || char == '(' || char == ')':
+			s.offset++
+			return string(char)
+		case char == '"':
+			return s.literal()
+		default:
+			start := s.offset
+			for s.offset < len(s.text) && !strings.ContainsRune(" \t\r\n{};[]()\"", rune(s.text[s.offset])) {
+				s.offset++
+			}
+			return s.text[start:s.offset]
+		}
+	}
+	return ``
+}
+
+// Skips text up to the given character keeping track of line numbers.
+func (s *pgnScanner) skipUntil(char byte) {
+	for ; s.offset < len(s.text) && s.text[s.offset] != char; s.offset++ {
+		if s.text[s.offset] == '\n' {
+			s.line++
+		}
+	}
+}
+
+// Reads string literal handling escaped quotes and backslashes. The result keeps
+// leading double quote to tell it from the symbol.
+func (s *pgnScanner) literal() string {
+	str := `"`
+	for s.offset++; s.offset < len(s.text) && s.text[s.offset] != '"'; s.offset++ {
+		if s.text[s.offset] == '\\' && s.offset + 1 < len(s.text) {
+			s.offset++
+		}
+		str += string(s.text[s.offset])
+	}
+	s.offset++
+	return str
+}
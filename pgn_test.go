@@ -0,0 +1,64 @@
+package kingside
+
+import `testing`
+
+func TestPgnMainLine(t *testing.T) {
+	games, err := NewPgn(`[Event "test"]
+[White "one"]
+[Black "two"]
+[Result "1-0"]
+
+1. e4 {comment} e5 2. Nf3 (2. f4 exf4) Nc6 $1 3.Bb5 ; rest of the line
+3... a6 1-0
+
+1. d4 d5 *
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(games) != 2 {
+		t.Fatalf("expected 2 games, got %d", len(games))
+	}
+	if games[0].tags[`White`] != `one` || games[0].result != `1-0` || len(games[0].moves) != 6 {
+		t.Errorf("unexpected first game %v %s %v", games[0].tags, games[0].result, games[0].moves)
+	}
+	if games[1].result != `*` || len(games[1].moves) != 2 {
+		t.Errorf("unexpected second game %s %v", games[1].result, games[1].moves)
+	}
+
+	game, position, err := games[0].replay()
+	if err != nil || len(game.moves) != 6 {
+		t.Fatalf("unexpected replay %v", err)
+	}
+	if fen := position.fen(); fen != `r1bqkbnr/1ppp1ppp/p1n5/1B2p3/4P3/5N2/PPPP1PPP/RNBQK2R w KQkq - 0 4` {
+		t.Errorf("unexpected final position %s", fen)
+	}
+}
+
+func TestPgnSetup(t *testing.T) {
+	games, err := NewPgn(`[FEN "4k3/8/8/8/8/8/8/R3K2R w K - 0 1"]` + "\n\n1. O-O Kd7 *\n")
+	if err != nil || len(games) != 1 {
+		t.Fatalf("unexpected games %v %v", games, err)
+	}
+	if _, position, err := games[0].replay(); err != nil || position.fen() != `8/3k4/8/8/8/8/8/R4RK1 w - - 2 2` {
+		t.Errorf("unexpected replay %v", err)
+	}
+}
+
+func TestPgnInvalid(t *testing.T) {
+	for _, text := range []string{ `1. e4 e4 *`, `[Event test]`, `1. e4 ) e5 *` } {
+		if _, err := NewPgn(text); err == nil {
+			t.Errorf("expected error for %q", text)
+		}
+	}
+}
+
+func TestPgnWrite(t *testing.T) {
+	games, _ := NewPgn(`1. f3 e5 2. g4 Qh4# 0-1`)
+	game, position, _ := games[0].replay()
+	pgn := game.pgn(position, map[string]string{ `Event`: `test` })
+	replayed, err := NewPgn(pgn)
+	if err != nil || len(replayed) != 1 || len(replayed[0].moves) != 4 || replayed[0].result != `0-1` {
+		t.Errorf("unable to read back written game: %v\n%s", err, pgn)
+	}
+}
@@ -3,13 +3,14 @@ package kingside
 import (
 	`bytes`
 	`fmt`
+	`strconv`
 	`strings`
 )
 
 var tree [1024]Position
 var node, rootNode int
 
-type Position struct {       // 224 bytes long.
+type Position struct {       // 240 bytes long.
 	hash         uint64      // Polyglot hash value for the position.
 	pawnHash     uint64      // Polyglot hash value for position's pawn structure.
 	board        Bitmask     // Bitmask of all pieces on the board.
@@ -17,19 +18,32 @@ type Position struct {       // 224 bytes long.
 	pieces       [64]Piece   // Array of 64 squares with pieces on them.
 	outposts     [14]Bitmask // Bitmasks of each piece on the board; [0] all white, [1] all black.
 	balance      int         // Material balance index.
+	halfmove     int         // Half-moves since last capture or pawn move.
+	fullmove     int         // Full move number, incremented after Black's move.
 	reversible   bool        // Is this position reversible?
 	color        uint8       // Side to make next move.
 	enpassant    uint8       // En-passant square caused by previous move.
 	castles      uint8       // Castle rights mask.
 }
 
-func NewPosition(game *Game, white, black string) *Position {
-	tree[node] = Position{}
+func NewPosition(game *Game, white, black string) (*Position, error) {
+	tree[node] = Position{ fullmove: 1 }
 	p := &tree[node]
 
-	p.setupSide(white, White).setupSide(black, Black)
+	if err := p.setupSide(white, White); err != nil {
+		return nil, err
+	}
+	if err := p.setupSide(black, Black); err != nil {
+		return nil, err
+	}
 
-	p.castles = castleKingside[White] | castleQueenside[White] | castleKingside[Black] | castleQueenside[Black]
+	// All castles are allowed unless the side specifies its castle rights
+	// explicitly.
+	for color := uint8(White); color <= uint8(Black); color++ {
+		if p.castles & (castleKingside[color] | castleQueenside[color]) == 0 {
+			p.castles |= castleKingside[color] | castleQueenside[color]
+		}
+	}
 	if p.pieces[E1] != King || p.pieces[H1] != Rook {
 		p.castles &= ^castleKingside[White]
 	}
@@ -55,9 +69,13 @@ func NewPosition(game *Game, white, black string) *Position {
 
 	p.reversible = true
 	p.board = p.outposts[White] | p.outposts[Black]
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
 	p.hash, p.pawnHash = p.polyglot()
+	p.balance = p.material()
 
-	return p
+	return p, nil
 }
 
 // Parses Donna chess format string for one side. Besides [K]ing, [Q]ueen, [R]ook,
@@ -78,19 +96,29 @@ func NewPosition(game *Game, white, black string) *Position {
 // [E]npassant: specifies en-passant square if any. For example, "Ed3" marks D3
 //              square as en-passant. Default value is no en-passant.
 //
-func (p *Position) setupSide(str string, color uint8) *Position {
-	invalid := func (move string, color uint8) {
-		// Don't panic.
-		panic(fmt.Sprintf("Invalid notation '%s' for %s\n", move, C(color)))
+func (p *Position) setupSide(str string, color uint8) error {
+	invalid := func (move string, color uint8) error {
+		return fmt.Errorf("invalid notation '%s' for %s", move, C(color))
 	}
 
-	for _, move := range strings.Split(str, `,`) {
+	for _, move := range strings.Split(strings.TrimSpace(str), `,`) {
+		move = strings.TrimSpace(move)
+		if move == `` {
+			return invalid(move, color)
+		}
 		if move[0] == 'M' {
 			p.color = color
+			if len(move) > 1 {
+				n, err := strconv.Atoi(move[1:])
+				if err != nil || n < 1 {
+					return invalid(move, color)
+				}
+				p.fullmove = n
+			}
 		} else {
 			arr := reMove.FindStringSubmatch(move)
-			if len(arr) == 0 {
-				invalid(move, color)
+			if len(arr) == 0 || arr[0] != move {
+				return invalid(move, color)
 			}
 			square := square(int(arr[3][0]-'1'), int(arr[2][0]-'a'))
 
@@ -108,9 +136,9 @@ func (p *Position) setupSide(str string, color uint8) *Position {
 			case 'E':
 				p.enpassant = uint8(square)
 			case 'C':
-				if (square == C1 + int(color)) || (square == C8 + int(color)) {
+				if square == C1 + A8 * int(color) {
 					p.castles |= castleQueenside[color]
-				} else if (square == G1 + int(color)) || (square == G8 + int(color)) {
+				} else if square == G1 + A8 * int(color) {
 					p.castles |= castleKingside[color]
 				}
 			default:
@@ -120,17 +148,19 @@ func (p *Position) setupSide(str string, color uint8) *Position {
 		}
 	}
 
-	return p
+	return nil
 }
 
 // Sets up initial chess position.
 func NewInitialPosition(game *Game) *Position {
-	return NewPositionFromFEN(game, `rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1`)
+	p, _ := NewPositionFromFEN(game, `rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1`)
+	return p
 }
 
-// Decodes FEN string and creates new position.
-func NewPositionFromFEN(game *Game, fen string) *Position {
-	tree[node] = Position{}
+// Decodes FEN string and creates new position. Malformed or illegal positions
+// are reported as errors.
+func NewPositionFromFEN(game *Game, fen string) (*Position, error) {
+	tree[node] = Position{ fullmove: 1 }
 	p := &tree[node]
 
 	// Expected matches of interest are as follows:
@@ -140,60 +170,77 @@ func NewPositionFromFEN(game *Game, fen string) *Position {
 	// [3] - En-passant square.
 	// [4] - Number of half-moves.
 	// [5] - Number of full moves.
-	matches := strings.Split(fen, ` `)
+	matches := strings.Fields(fen)
 	if len(matches) < 4 {
-		return nil
+		return nil, fmt.Errorf("invalid FEN '%s': expected at least 4 fields", fen)
 	}
 
 	// [0] - Pieces (entire board).
-	sq := A8
-	for _, char := range(matches[0]) {
-		piece := Piece(0)
-		switch(char) {
-		case 'P':
-			piece = Pawn
-		case 'p':
-			piece = BlackPawn
-		case 'N':
-			piece = Knight
-		case 'n':
-			piece = BlackKnight
-		case 'B':
-			piece = Bishop
-		case 'b':
-			piece = BlackBishop
-		case 'R':
-			piece = Rook
-		case 'r':
-			piece = BlackRook
-		case 'Q':
-			piece = Queen
-		case 'q':
-			piece = BlackQueen
-		case 'K':
-			piece = King
-			p.king[White] = uint8(sq)
-		case 'k':
-			piece = BlackKing
-			p.king[Black] = uint8(sq)
-		case '/':
-			sq -= 16
-		case '1', '2', '3', '4', '5', '6', '7', '8':
-			sq += int(char - '0')
-		}
-		if piece != 0 {
+	ranks := strings.Split(matches[0], `/`)
+	if len(ranks) != 8 {
+		return nil, fmt.Errorf("invalid FEN board '%s': expected 8 ranks, got %d", matches[0], len(ranks))
+	}
+	for i, pieces := range ranks {
+		row, col := A8H8 - i, 0
+		for _, char := range pieces {
+			if char >= '1' && char <= '8' {
+				col += int(char - '0')
+				continue
+			}
+			piece := Piece(0)
+			switch char {
+			case 'P':
+				piece = Pawn
+			case 'p':
+				piece = BlackPawn
+			case 'N':
+				piece = Knight
+			case 'n':
+				piece = BlackKnight
+			case 'B':
+				piece = Bishop
+			case 'b':
+				piece = BlackBishop
+			case 'R':
+				piece = Rook
+			case 'r':
+				piece = BlackRook
+			case 'Q':
+				piece = Queen
+			case 'q':
+				piece = BlackQueen
+			case 'K':
+				piece = King
+			case 'k':
+				piece = BlackKing
+			default:
+				return nil, fmt.Errorf("invalid FEN rank %d '%s': unexpected character '%c'", row + 1, pieces, char)
+			}
+			if col > 7 {
+				return nil, fmt.Errorf("invalid FEN rank %d '%s': expected 8 squares", row + 1, pieces)
+			}
+			sq := square(row, col)
 			p.pieces[sq] = piece
 			p.outposts[piece].set(sq)
 			p.outposts[piece.color()].set(sq)
-			sq++
+			if piece.isKing() {
+				p.king[piece.color()] = uint8(sq)
+			}
+			col++
+		}
+		if col != 8 {
+			return nil, fmt.Errorf("invalid FEN rank %d '%s': expected 8 squares", row + 1, pieces)
 		}
 	}
 
 	// [1] - Color of side to move.
-	if matches[1] == `w` {
+	switch matches[1] {
+	case `w`:
 		p.color = White
-	} else {
+	case `b`:
 		p.color = Black
+	default:
+		return nil, fmt.Errorf("invalid FEN side to move '%s'", matches[1])
 	}
 
 	// [2] - Castle rights.
@@ -209,20 +256,97 @@ func NewPositionFromFEN(game *Game, fen string) *Position {
 			p.castles |= castleQueenside[Black]
 		case '-':
 			// No castling rights.
+		default:
+			return nil, fmt.Errorf("invalid FEN castle rights '%s'", matches[2])
 		}
 	}
 
 	// [3] - En-passant square.
 	if matches[3] != `-` {
+		arr := reMove.FindStringSubmatch(matches[3])
+		if len(arr) == 0 || arr[0] != matches[3] || arr[1] != `` {
+			return nil, fmt.Errorf("invalid FEN en-passant square '%s'", matches[3])
+		}
 		p.enpassant = uint8(square(int(matches[3][1] - '1'), int(matches[3][0] - 'a')))
+	}
+
+	// [4] - Number of half-moves.
+	if len(matches) > 4 {
+		n, err := strconv.Atoi(matches[4])
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid FEN half-move clock '%s'", matches[4])
+		}
+		p.halfmove = n
+	}
 
+	// [5] - Number of full moves.
+	if len(matches) > 5 {
+		n, err := strconv.Atoi(matches[5])
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid FEN full move number '%s'", matches[5])
+		}
+		p.fullmove = n
 	}
 
 	p.reversible = true
 	p.board = p.outposts[White] | p.outposts[Black]
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
 	p.hash, p.pawnHash = p.polyglot()
+	p.balance = p.material()
 
-	return p
+	return p, nil
+}
+
+// Makes sure the position that has been set up is legal: each side has exactly
+// one king, there are no pawns on the first and last ranks, the side that has
+// just moved is not in check, and castle rights and en-passant square match
+// the pieces on the board.
+func (p *Position) validate() error {
+	for color := uint8(White); color <= uint8(Black); color++ {
+		switch count := p.outposts[king(color)].count(); {
+		case count == 0:
+			return fmt.Errorf("missing %s king", C(color))
+		case count > 1:
+			return fmt.Errorf("%s has %d kings", C(color), count)
+		}
+	}
+
+	if (p.outposts[Pawn] | p.outposts[BlackPawn]) & (maskRank[0] | maskRank[7]) != 0 {
+		return fmt.Errorf("pawns can't be placed on the first or last rank")
+	}
+
+	if p.isInCheck(p.color ^ 1) {
+		return fmt.Errorf("%s is in check while %s is to move", C(p.color ^ 1), C(p.color))
+	}
+
+	if p.castles & castleKingside[White] != 0 && (p.pieces[E1] != King || p.pieces[H1] != Rook) {
+		return fmt.Errorf("white can't castle kingside without king on e1 and rook on h1")
+	}
+	if p.castles & castleQueenside[White] != 0 && (p.pieces[E1] != King || p.pieces[A1] != Rook) {
+		return fmt.Errorf("white can't castle queenside without king on e1 and rook on a1")
+	}
+	if p.castles & castleKingside[Black] != 0 && (p.pieces[E8] != BlackKing || p.pieces[H8] != BlackRook) {
+		return fmt.Errorf("black can't castle kingside without king on e8 and rook on h8")
+	}
+	if p.castles & castleQueenside[Black] != 0 && (p.pieces[E8] != BlackKing || p.pieces[A8] != BlackRook) {
+		return fmt.Errorf("black can't castle queenside without king on e8 and rook on a8")
+	}
+
+	// En-passant square must be right behind the enemy pawn that has just
+	// made two-square jump, i.e. on the 6th rank for White to move and on
+	// the 3rd rank for Black.
+	if p.enpassant != 0 {
+		square := int(p.enpassant)
+		jumped := square - eight[p.color]
+		if rank(p.color, square) != 5 || p.pieces[jumped] != pawn(p.color ^ 1) ||
+		   p.pieces[square] != 0 || p.pieces[square + eight[p.color]] != 0 {
+			return fmt.Errorf("impossible en-passant square %c%d", col(square) + 'a', row(square) + 1)
+		}
+	}
+
+	return nil
 }
 
 // Computes initial values of position's polyglot hash, pawn hash, and material
@@ -250,9 +374,34 @@ func (p *Position) polyglot() (hash, pawnHash uint64) {
 	return
 }
 
+// Computes initial value of position's material balance index. When making a
+// move the balance gets updated incrementally.
+func (p *Position) material() (balance int) {
+	board := p.board
+	for board != 0 {
+		balance += materialBalance[p.pieces[board.pop()]]
+	}
+	return
+}
+
 // Returns true if material balance is insufficient to win the game.
 func (p *Position) insufficient() bool {
-	return false // TODO
+	// Any pawn, rook, or queen on the board is enough to win.
+	if p.outposts[Pawn] | p.outposts[BlackPawn] | p.outposts[Rook] | p.outposts[BlackRook] | p.outposts[Queen] | p.outposts[BlackQueen] != 0 {
+		return false
+	}
+
+	knights := p.outposts[Knight] | p.outposts[BlackKnight]
+	bishops := p.outposts[Bishop] | p.outposts[BlackBishop]
+
+	// King vs. king, or any number of bishops of either side that reside on
+	// the same colored squares.
+	if knights == 0 {
+		return bishops & maskDark == 0 || bishops & ^maskDark == 0
+	}
+
+	// Single knight with no bishops.
+	return bishops == 0 && knights.count() == 1
 }
 
 // Reports game status for current position or after the given move. The status
@@ -287,7 +436,8 @@ func (p *Position) status(move Move, blendedScore int) int {
 		return Stalemate
 	default:
 		if score > Checkmate-MaxDepth && (score+ply)/2 > 0 {
-			if p.color == White {
+			// Positive score means the side that made the move is winning.
+			if (p.color == White) == (blendedScore > 0) {
 				return BlackWinning
 			}
 			return WhiteWinning
@@ -364,7 +514,8 @@ func (p *Position) fen() (fen string) {
 		fen += ` -`
 	}
 
-	fen += ` 0 1`
+	// Half-move clock and full move number.
+	fen += fmt.Sprintf(` %d %d`, p.halfmove, p.fullmove)
 
 	return
 }
@@ -386,9 +537,14 @@ func (p *Position) dcf() string {
 	var pieces [2][]string
 
 	for color := uint8(White); color <= uint8(Black); color++ {
-		// Right to move.
-		if color == p.color && color == Black {
-			pieces[color] = append(pieces[color], `M`)
+		// Right to move along with the move number unless it's the
+		// first move.
+		if color == p.color {
+			if p.fullmove > 1 {
+				pieces[color] = append(pieces[color], fmt.Sprintf(`M%d`, p.fullmove))
+			} else if color == Black {
+				pieces[color] = append(pieces[color], `M`)
+			}
 		}
 
 		// King.
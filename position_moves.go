@@ -25,6 +25,7 @@ func (p *Position) promotePawn(pawn Piece, from, to int, promo Piece) *Position
 	random := pawn.polyglot(from)
 	p.hash ^= random ^ promo.polyglot(to)
 	p.pawnHash ^= random
+	p.balance += materialBalance[promo]
 
 	return p
 }
@@ -39,6 +40,7 @@ func (p *Position) capturePiece(capture Piece, from, to int) *Position {
 	if capture.isPawn() {
 		p.pawnHash ^= random
 	}
+	p.balance -= materialBalance[capture]
 
 	return p
 }
@@ -69,6 +71,16 @@ func (p *Position) makeMove(move Move) *Position {
 
 	pp.enpassant, pp.reversible = 0, true
 
+	// Captures and pawn moves reset the half-move clock for the fifty moves
+	// rule. Full move number advances after Black's move.
+	pp.halfmove++
+	if capture != 0 || piece.isPawn() {
+		pp.halfmove = 0
+	}
+	if color == Black {
+		pp.fullmove++
+	}
+
 	if capture != 0 {
 		pp.reversible = false
 		if to != 0 && to == int(p.enpassant) {
@@ -162,17 +174,7 @@ func (p *Position) isNull() bool {
 }
 
 func (p *Position) fifty() bool {
-	if node < 100 {
-		return false
-	}
-	count := 0
-	for previous := node - 1; previous >= 0 && count < 100; previous-- {
-		if !tree[previous].reversible {
-			break
-		}
-		count++
-	}
-	return count >= 100
+	return p.halfmove >= 100
 }
 
 func (p *Position) repetition() bool {
@@ -0,0 +1,39 @@
+package kingside
+
+import `testing`
+
+func TestFenRoundTrip(t *testing.T) {
+	for _, fen := range []string{
+		initialFEN,
+		`r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1`,
+		`rnbqkbnr/ppp1pppp/8/8/3pP3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 3`,
+		`8/8/8/8/8/8/k7/1R5K b - - 37 80`,
+	} {
+		position, err := NewGame(fen).start()
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", fen, err)
+		} else if position.fen() != fen {
+			t.Errorf("expected %s, got %s", fen, position.fen())
+		}
+	}
+}
+
+func TestFenInvalid(t *testing.T) {
+	for _, fen := range []string{
+		`8/8/8/8 w - -`,
+		`rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP w KQkq - 0 1`,
+		`rnbqkbnr/ppppxppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1`,
+		`rnbqkbnr/pppppppp/9/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1`,
+		`rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR x KQkq - 0 1`,
+		`8/8/8/8/8/8/8/K7 w - - 0 1`,
+		`k7/8/8/8/8/8/8/KK6 w - - 0 1`,
+		`k6P/8/8/8/8/8/8/K7 w - - 0 1`,
+		`k7/8/8/8/8/8/8/R6K w - - 0 1`,
+		`k7/8/8/8/8/8/8/K7 w K - 0 1`,
+		`k7/8/8/8/8/8/8/K7 w - e6 0 1`,
+	} {
+		if _, err := NewGame(fen).start(); err == nil {
+			t.Errorf("%s: expected error", fen)
+		}
+	}
+}
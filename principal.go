@@ -0,0 +1,57 @@
+package kingside
+
+// Triangular principal variation table: each ply keeps the best line found from
+// that ply onward, and gets updated from the next ply's line whenever the search
+// finds a better move.
+type Principal struct {
+	size     int
+	moves    [MaxPly]Move
+}
+
+// Use single statically allocated array, one entry per ply.
+var principal [MaxPly+1]Principal
+
+// Clears principal variation for the given ply when entering the node.
+func resetPrincipal(ply int) {
+	principal[ply].size = 0
+}
+
+// Sets principal variation for the given ply to be the move followed by the
+// principal variation of the next ply.
+func updatePrincipal(ply int, move Move) {
+	pv, next := &principal[ply], &principal[ply+1]
+	pv.moves[0] = move
+	pv.size = 1
+	if ply + 1 < MaxPly {
+		size := min(next.size, MaxPly - 1)
+		copy(pv.moves[1:], next.moves[:size])
+		pv.size += size
+	}
+}
+
+// Rebuilds principal variation for the given ply from the transposition table
+// when the search gets cut off by exact cached score. The line follows valid
+// cached moves up to the remaining search depth.
+func cachedPrincipal(p *Position, ply, depth int) {
+	pv := &principal[ply]
+	pv.size = 0
+
+	position := p
+	for pv.size < min(depth, MaxPly - ply) {
+		move := position.cachedMove()
+		if move == Move(0) || !NewGen(position, MaxPly).generateAllMoves().validOnly().amongValid(move) {
+			break
+		}
+		pv.moves[pv.size] = move
+		pv.size++
+		position = position.makeMove(move)
+	}
+	for i := 0; i < pv.size; i++ {
+		position = position.undoLastMove()
+	}
+}
+
+// Returns a copy of the principal variation found by the root search.
+func rootPrincipal() []Move {
+	return append([]Move{}, principal[0].moves[:principal[0].size]...)
+}
@@ -0,0 +1,176 @@
+package kingside
+
+import (
+	`bytes`
+	`fmt`
+	`regexp`
+	`strings`
+)
+
+// Standard algebraic notation: [1] piece, [2] origin file, [3] origin rank, [4]
+// capture, [5] target square, [6] promotion, and [7] check or checkmate.
+var reSan = regexp.MustCompile(`^([KQRBN]?)([a-h]?)([1-8]?)(x?)([a-h][1-8])(?:=?([QRBN]))?([+#]?)[!?]{0,2}$`)
+
+// Decodes a string in standard algebraic notation, ex. `Nf3`, `exd5`, `Rad1`,
+// `e8=Q+`, or `O-O`, and returns the only valid move that matches it. Castles
+// could also be given with zeros, promotions without '=', and the move itself in
+// coordinate notation, ex. `g1f3`.
+func NewMoveFromSan(p *Position, san string) (Move, error) {
+	valid := NewGen(p, MaxPly).generateAllMoves().validOnly().allMoves()
+
+	// Castles.
+	if castle := strings.TrimRight(san, `+#!?`); strings.Trim(castle, `O0-`) == `` {
+		kingside := castle == `O-O` || castle == `0-0`
+		if kingside || castle == `O-O-O` || castle == `0-0-0` {
+			for _, move := range valid {
+				if move.isCastle() && (move.to() > move.from()) == kingside {
+					return move, nil
+				}
+			}
+		}
+		return Move(0), fmt.Errorf("invalid move '%s'", san)
+	}
+
+	// Coordinate notation as used by UCI, ex. `g1f3` or `e7e8q`.
+	for _, move := range valid {
+		if move.notation() == san {
+			return move, nil
+		}
+	}
+
+	matches := reSan.FindStringSubmatch(san)
+	if matches == nil {
+		return Move(0), fmt.Errorf("invalid move '%s'", san)
+	}
+
+	kind, promo := Pawn, 0
+	if matches[1] != `` {
+		kind = sanPiece(matches[1][0])
+	}
+	if matches[6] != `` {
+		promo = sanPiece(matches[6][0])
+	}
+	target := square(int(matches[5][1] - '1'), int(matches[5][0] - 'a'))
+
+	candidates := []Move{}
+	for _, move := range valid {
+		from := move.from()
+		if move.piece().kind() != kind || move.to() != target || move.isCastle() {
+			continue
+		}
+		if matches[2] != `` && col(from) != int(matches[2][0] - 'a') {
+			continue
+		}
+		if matches[3] != `` && row(from) != int(matches[3][0] - '1') {
+			continue
+		}
+		// Pawn captures always come with the origin file.
+		if kind == Pawn && matches[2] == `` && col(from) != col(target) {
+			continue
+		}
+		if matches[4] != `` && move.capture() == 0 {
+			continue
+		}
+		if move.isPromo() && move.promo().kind() != promo || !move.isPromo() && promo != 0 {
+			continue
+		}
+		candidates = append(candidates, move)
+	}
+
+	switch len(candidates) {
+	case 0:
+		return Move(0), fmt.Errorf("invalid move '%s'", san)
+	case 1:
+		return candidates[0], nil
+	}
+	return Move(0), fmt.Errorf("ambiguous move '%s'", san)
+}
+
+// Returns the move in standard algebraic notation, ex. `Nf3`, `exd5`, `Rad1`,
+// `e8=Q+`, or `O-O#`. The move is expected to be valid in the position.
+func (p *Position) san(move Move) string {
+	var buffer bytes.Buffer
+
+	from, to, piece, _ := move.split()
+	if move.isCastle() {
+		if to > from {
+			buffer.WriteString(`O-O`)
+		} else {
+			buffer.WriteString(`O-O-O`)
+		}
+	} else {
+		if piece.isPawn() {
+			if move.capture() != 0 {
+				buffer.WriteByte(byte(col(from)) + 'a')
+			}
+		} else {
+			buffer.WriteByte(piece.char())
+			buffer.WriteString(p.disambiguation(move))
+		}
+		if move.capture() != 0 {
+			buffer.WriteByte('x')
+		}
+		buffer.WriteByte(byte(col(to)) + 'a')
+		buffer.WriteByte(byte(row(to)) + '1')
+		if move.isPromo() {
+			buffer.WriteByte('=')
+			buffer.WriteByte(move.promo().char())
+		}
+	}
+
+	// Check or checkmate suffix.
+	position := p.makeMove(move)
+	if position.isInCheck(position.color) {
+		if NewGen(position, MaxPly).generateAllMoves().anyValid() {
+			buffer.WriteByte('+')
+		} else {
+			buffer.WriteByte('#')
+		}
+	}
+	position.undoLastMove()
+
+	return buffer.String()
+}
+
+// Returns file, rank, or both of the origin square when other piece of the same
+// kind could move to the same target square.
+func (p *Position) disambiguation(move Move) string {
+	from, to, piece, _ := move.split()
+
+	ambiguous, sameCol, sameRow := false, false, false
+	for _, other := range NewGen(p, MaxPly).generateAllMoves().validOnly().allMoves() {
+		if other.piece() == piece && other.to() == to && other.from() != from {
+			ambiguous = true
+			sameCol = sameCol || col(other.from()) == col(from)
+			sameRow = sameRow || row(other.from()) == row(from)
+		}
+	}
+
+	file, rank := string(byte(col(from)) + 'a'), string(byte(row(from)) + '1')
+	switch {
+	case !ambiguous:
+		return ``
+	case !sameCol:
+		return file
+	case !sameRow:
+		return rank
+	}
+	return file + rank
+}
+
+// Returns piece kind for the SAN piece letter or 0 if there is no such piece.
+func sanPiece(letter byte) int {
+	switch letter {
+	case 'K':
+		return King
+	case 'Q':
+		return Queen
+	case 'R':
+		return Rook
+	case 'B':
+		return Bishop
+	case 'N':
+		return Knight
+	}
+	return 0
+}
@@ -0,0 +1,58 @@
+package kingside
+
+import `testing`
+
+func TestSanDecode(t *testing.T) {
+	tests := []struct{ fen, san, move string }{
+		{ initialFEN, `Nf3`, `g1f3` },
+		{ initialFEN, `e4`, `e2e4` },
+		{ initialFEN, `g1f3`, `g1f3` },
+		{ `r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1`, `O-O`, `e1g1` },
+		{ `r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1`, `0-0-0`, `e8c8` },
+		{ `4k3/8/8/8/8/8/4K3/R6R w - - 0 1`, `Rad1`, `a1d1` },
+		{ `4k3/P7/8/8/8/8/8/4K3 w - - 0 1`, `a8=Q+`, `a7a8q` },
+		{ `4k3/P7/8/8/8/8/8/4K3 w - - 0 1`, `a8N`, `a7a8n` },
+		{ `4k3/8/8/3p4/4P3/8/8/4K3 w - - 0 1`, `exd5`, `e4d5` },
+	}
+	for _, test := range tests {
+		position, _ := NewGame(test.fen).start()
+		move, err := NewMoveFromSan(position, test.san)
+		if err != nil || move.notation() != test.move {
+			t.Errorf("%s: expected %s for %s, got %s %v", test.fen, test.move, test.san, move.notation(), err)
+		}
+	}
+}
+
+func TestSanInvalid(t *testing.T) {
+	tests := []struct{ fen, san string }{
+		{ initialFEN, `Nf4` },
+		{ initialFEN, `O-O` },
+		{ initialFEN, `e5` },
+		{ `4k3/8/8/8/8/8/4K3/R6R w - - 0 1`, `Rd1` }, // Ambiguous.
+		{ initialFEN, `xyz` },
+	}
+	for _, test := range tests {
+		position, _ := NewGame(test.fen).start()
+		if move, err := NewMoveFromSan(position, test.san); err == nil {
+			t.Errorf("%s: expected error for %s, got %s", test.fen, test.san, move.notation())
+		}
+	}
+}
+
+func TestSanEncode(t *testing.T) {
+	tests := []struct{ fen, move, san string }{
+		{ initialFEN, `g1f3`, `Nf3` },
+		{ `r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1`, `e1c1`, `O-O-O` },
+		{ `4k3/8/8/8/8/8/4K3/R6R w - - 0 1`, `a1d1`, `Rad1` },
+		{ `4k3/P7/8/8/8/8/8/4K3 w - - 0 1`, `a7a8q`, `a8=Q+` },
+		{ `4k3/8/8/3p4/4P3/8/8/4K3 w - - 0 1`, `e4d5`, `exd5` },
+		{ `6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1`, `a1a8`, `Ra8#` },
+	}
+	for _, test := range tests {
+		position, _ := NewGame(test.fen).start()
+		move, _ := NewMoveFromSan(position, test.move)
+		if san := position.san(move); san != test.san {
+			t.Errorf("%s: expected %s for %s, got %s", test.fen, test.san, test.move, san)
+		}
+	}
+}
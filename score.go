@@ -0,0 +1,31 @@
+package kingside
+
+// Evaluation score with separate midgame and endgame values. The final score is
+// blended between the two based on the game phase.
+type Score struct {
+	midgame  int
+	endgame  int
+}
+
+func (s *Score) add(score Score) *Score {
+	s.midgame += score.midgame
+	s.endgame += score.endgame
+	return s
+}
+
+func (s *Score) subtract(score Score) *Score {
+	s.midgame -= score.midgame
+	s.endgame -= score.endgame
+	return s
+}
+
+// Returns the score multiplied by the given factor.
+func (s Score) times(factor int) Score {
+	return Score{ s.midgame * factor, s.endgame * factor }
+}
+
+// Returns tapered score for the game phase that ranges from maxBalance for the
+// initial position down to zero when only kings and pawns are left.
+func (s Score) blended(phase int) int {
+	return (s.midgame * phase + s.endgame * (maxBalance - phase)) / maxBalance
+}
@@ -0,0 +1,24 @@
+#!/bin/sh
+# Mock UCI engine for the client tests. It replies to "go" with the moves given
+# as arguments, one move per search, and keeps silent when it runs out of moves.
+while read -r command rest; do
+	case $command in
+	uci)
+		echo "id name mock"
+		echo "uciok"
+		;;
+	isready)
+		echo "readyok"
+		;;
+	go)
+		if [ $# -gt 0 ]; then
+			echo "info depth 1 score cp 10 nodes 1 pv $1"
+			echo "bestmove $1"
+			shift
+		fi
+		;;
+	quit)
+		exit 0
+		;;
+	esac
+done
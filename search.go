@@ -4,8 +4,13 @@ package kingside
 // something in the last place you look.
 func (p *Position) search(alpha, beta, depth int) (bestScore int, bestMove Move) {
 	gen := NewRootGen(p, depth)
-	gen.generateRootMoves()
+	if depth == 1 {
+		gen.generateRootMoves().prioritize(p.cachedMove())
+	} else {
+		gen.reset()
+	}
 
+	resetPrincipal(0)
 	inCheck := p.isInCheck(p.color)
 	moveCount := 0
 	bestScore = -Checkmate
@@ -14,11 +19,26 @@ func (p *Position) search(alpha, beta, depth int) (bestScore int, bestMove Move)
 		moveCount++
 
 		position := p.makeMove(move)
-		score := -position.negamax(depth + 1)
+		score := -position.negamax(-beta, -alpha, depth - 1)
 		position.undoLastMove()
+		if engine.clock.halted() {
+			if bestMove == Move(0) {
+				bestMove = move // Better than nothing.
+			}
+			break
+		}
+		gen.setScore(score)
+
 		if score > bestScore {
 			bestScore = score
 			bestMove = move
+			if score > alpha {
+				alpha = score
+				updatePrincipal(0, move)
+				if alpha >= beta {
+					break
+				}
+			}
 		}
 	}
 
@@ -28,15 +48,67 @@ func (p *Position) search(alpha, beta, depth int) (bestScore int, bestMove Move)
 		} else {
 			bestScore = 0
 		}
-		if engine.uci {
-			engine.uciScore(depth, bestScore, alpha, beta)
-		}
 	}
 
+	if !engine.clock.halted() && bestMove != Move(0) {
+		p.cache(bestMove, bestScore, depth, 0, cacheExact)
+	}
+
+	// Rearrange root moves by their scores so that next iteration of
+	// iterative deepening starts with the best move found so far.
+	gen.sort()
+
 	return bestScore, bestMove
 }
 
-func (p *Position) negamax(depth int) (bestScore int) {
+// Depth-limited negamax search with alpha-beta pruning. The score is always
+// returned from the point of view of the side to move, and mate scores are
+// adjusted by the distance from the root so that quicker mates score higher.
+func (p *Position) negamax(alpha, beta, depth int) (bestScore int) {
+	if engine.clock.halted() {
+		return 0
+	}
+
+	// Repetition, fifty moves rule, or insufficient material end the game
+	// in a draw regardless of what is left to search.
+	if p.repetition() || p.fifty() || p.insufficient() {
+		resetPrincipal(ply())
+		return 0
+	}
+
+	if depth <= 0 {
+		return p.quiescence(alpha, beta, 0)
+	}
+	stats.node()
+	resetPrincipal(ply())
+	if ply() >= MaxPly - 1 {
+		return p.Evaluate()
+	}
+
+	// Probe the transposition table: if the position has been searched deep
+	// enough we might get the cutoff right away. Otherwise we still try the
+	// cached best move first.
+	cachedMove := Move(0)
+	if entry := p.probeCache(); entry != nil {
+		cachedMove = entry.move
+		if int(entry.depth) >= depth {
+			score := uncache(int(entry.score), ply())
+			switch entry.flags {
+			case cacheExact:
+				cachedPrincipal(p, ply(), depth)
+				return score
+			case cacheAlpha:
+				if score <= alpha {
+					return score
+				}
+			case cacheBeta:
+				if score >= beta {
+					return score
+				}
+			}
+		}
+	}
+
 	gen := NewMoveGen(p)
 	inCheck := p.isInCheck(p.color)
 	if inCheck {
@@ -44,25 +116,50 @@ func (p *Position) negamax(depth int) (bestScore int) {
 	} else {
 		gen.generateMoves()
 	}
+	gen.prioritize(cachedMove)
 
 	moveCount := 0
 	bestScore = -Checkmate
+	bestMove := Move(0)
+	cacheFlags := uint8(cacheAlpha)
 	for move := gen.NextMove(); move != 0; move = gen.NextMove() {
+		if !gen.isValid(move) {
+			continue
+		}
 		moveCount++
 
 		position := p.makeMove(move)
-		score := 0 // TODO
+		score := -position.negamax(-beta, -alpha, depth - 1)
 		position.undoLastMove()
-		bestScore = max(score, bestScore)
+		if engine.clock.halted() {
+			return 0
+		}
+
+		if score > bestScore {
+			bestScore = score
+			if score > alpha {
+				alpha = score
+				bestMove = move
+				cacheFlags = cacheExact
+				updatePrincipal(ply(), move)
+				if alpha >= beta {
+					cacheFlags = cacheBeta
+					break
+				}
+			}
+		}
 	}
 
 	if moveCount == 0 {
 		if inCheck {
-			bestScore = -Checkmate
+			bestScore = -Checkmate + ply()
 		} else {
 			bestScore = 0
 		}
+		cacheFlags = cacheExact
 	}
 
+	p.cache(bestMove, bestScore, depth, ply(), cacheFlags)
+
 	return bestScore
 }
@@ -0,0 +1,102 @@
+package kingside
+
+// Quiescence search resolves tactical sequences beyond the search horizon so that
+// we never evaluate positions in the middle of an exchange. The depth starts at
+// zero and goes negative; quiet checks are only tried at the very first ply.
+func (p *Position) quiescence(alpha, beta, depth int) (bestScore int) {
+	if engine.clock.halted() {
+		return 0
+	}
+	stats.qnode()
+	resetPrincipal(ply())
+	if ply() >= MaxPly - 1 {
+		return p.Evaluate()
+	}
+
+	// Unless we're in check the side to move can always "stand pat", i.e.
+	// refuse to capture if the static evaluation is good enough already.
+	inCheck := p.isInCheck(p.color)
+	bestScore = -Checkmate + ply()
+	if !inCheck {
+		bestScore = p.Evaluate()
+		if bestScore >= beta {
+			return bestScore
+		}
+		alpha = max(alpha, bestScore)
+	}
+
+	gen := NewMoveGen(p)
+	if inCheck {
+		gen.generateEvasions()
+	} else {
+		gen.generateCaptures().rankCaptures()
+	}
+
+	moveCount := 0
+	for move := gen.NextMove(); move != 0; move = gen.NextMove() {
+		if !gen.isValid(move) {
+			continue
+		}
+		moveCount++
+
+		// Underpromotions are not worth looking at beyond the horizon.
+		if !inCheck && move.isPromo() && !move.promo().isQueen() {
+			continue
+		}
+
+		position := p.makeMove(move)
+		score := -position.quiescence(-beta, -alpha, depth - 1)
+		position.undoLastMove()
+		if engine.clock.halted() {
+			return 0
+		}
+
+		if score > bestScore {
+			bestScore = score
+			if score > alpha {
+				alpha = score
+				updatePrincipal(ply(), move)
+				if alpha >= beta {
+					return
+				}
+			}
+		}
+	}
+
+	if inCheck {
+		if moveCount == 0 {
+			return -Checkmate + ply()
+		}
+		return
+	}
+
+	// Non-capturing checks at the first quiescence ply.
+	if depth == 0 {
+		gen = NewMoveGen(p).generateChecks()
+		for move := gen.NextMove(); move != 0; move = gen.NextMove() {
+			if move.isCapture() || !gen.isValid(move) {
+				continue
+			}
+
+			position := p.makeMove(move)
+			score := -position.quiescence(-beta, -alpha, depth - 1)
+			position.undoLastMove()
+			if engine.clock.halted() {
+				return 0
+			}
+
+			if score > bestScore {
+				bestScore = score
+				if score > alpha {
+					alpha = score
+					updatePrincipal(ply(), move)
+					if alpha >= beta {
+						return
+					}
+				}
+			}
+		}
+	}
+
+	return
+}
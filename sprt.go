@@ -0,0 +1,87 @@
+package kingside
+
+import `math`
+
+// Sequential probability ratio test: H0 says the first engine is elo0 stronger
+// than the second one, and H1 says it is elo1 stronger. Alpha and beta are the
+// probabilities of false positive and false negative respectively.
+type Sprt struct {
+	elo0     float64
+	elo1     float64
+	lower    float64  // Accept H0 when log-likelihood ratio drops below.
+	upper    float64  // Accept H1 when log-likelihood ratio rises above.
+	maxGames int      // Give up without decision after that many games.
+}
+
+func NewSprt(elo0, elo1, alpha, beta float64) *Sprt {
+	return &Sprt{
+		elo0:     elo0,
+		elo1:     elo1,
+		lower:    math.Log(beta / (1.0 - alpha)),
+		upper:    math.Log((1.0 - beta) / alpha),
+		maxGames: 20000,
+	}
+}
+
+// Sets the number of games after which the test gets stopped without accepting
+// either hypothesis.
+func (s *Sprt) Limit(games int) *Sprt {
+	s.maxGames = games
+	return s
+}
+
+// Returns log-likelihood ratio of H1 vs. H0 for the game results using normal
+// approximation of the score distribution. The variance gets estimated with one
+// extra win and one extra loss so that it never drops to zero when all the games
+// end the same way.
+func (s *Sprt) llr(wins, draws, losses int) float64 {
+	games := float64(wins + draws + losses)
+	if games == 0.0 {
+		return 0.0
+	}
+	score := (float64(wins) + float64(draws) / 2.0) / games
+
+	w, d := float64(wins + 1) / (games + 2.0), float64(draws) / (games + 2.0)
+	mean := w + d / 2.0
+	variance := w + d / 4.0 - mean * mean
+
+	s0, s1 := expectedScore(s.elo0), expectedScore(s.elo1)
+	return games * (s1 - s0) * (2.0 * score - s0 - s1) / (2.0 * variance)
+}
+
+// Plays pairs of games cycling through the openings until either hypothesis
+// gets accepted or the games limit is reached. Reports log-likelihood ratio
+// after each pair.
+func (m *Match) Sprt(sprt *Sprt) *Match {
+	saved := engine
+	defer func() { engine = saved }()
+
+	engine.reply("SPRT elo0 %.1f elo1 %.1f, LLR bounds [%.2f, %.2f]\n", sprt.elo0, sprt.elo1, sprt.lower, sprt.upper)
+	for i := 0; ; i++ {
+		index := i % len(m.openings)
+		if err := m.pair(index, m.openings[index]); err != nil {
+			engine.reply("%3d) %s: %s\n", index + 1, m.openings[index].fen, err)
+			break
+		}
+
+		llr := sprt.llr(m.wins, m.draws, m.losses)
+		engine.reply("LLR %.2f [%.2f, %.2f]\n", llr, sprt.lower, sprt.upper)
+		if llr >= sprt.upper {
+			engine.reply("\nH1 accepted: %s\n", m)
+			break
+		} else if llr <= sprt.lower {
+			engine.reply("\nH0 accepted: %s\n", m)
+			break
+		} else if m.wins + m.draws + m.losses >= sprt.maxGames {
+			engine.reply("\nNo decision after %d games: %s\n", sprt.maxGames, m)
+			break
+		}
+	}
+
+	return m
+}
+
+// Converts Elo rating difference to expected score.
+func expectedScore(elo float64) float64 {
+	return 1.0 / (1.0 + math.Pow(10.0, -elo / 400.0))
+}
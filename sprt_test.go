@@ -0,0 +1,45 @@
+package kingside
+
+import `testing`
+
+func TestSprtLlr(t *testing.T) {
+	sprt := NewSprt(0, 5, 0.05, 0.05)
+	if llr := sprt.llr(0, 0, 0); llr != 0.0 {
+		t.Errorf("expected zero LLR without games, got %.2f", llr)
+	}
+
+	// All wins or all losses should get decided quickly.
+	if llr := sprt.llr(50, 0, 0); llr < sprt.upper {
+		t.Errorf("expected H1 after 50 wins, got LLR %.2f", llr)
+	}
+	if llr := sprt.llr(0, 0, 50); llr > sprt.lower {
+		t.Errorf("expected H0 after 50 losses, got LLR %.2f", llr)
+	}
+	if llr := sprt.llr(0, 50, 0); llr >= 0.0 || llr <= sprt.lower {
+		t.Errorf("expected slightly negative LLR after 50 draws, got %.2f", llr)
+	}
+
+	// Even score favors H0, and more wins than losses favor H1.
+	if llr := sprt.llr(100, 100, 100); llr >= 0.0 {
+		t.Errorf("expected negative LLR for even score, got %.2f", llr)
+	}
+	if llr := sprt.llr(120, 100, 80); llr <= 0.0 {
+		t.Errorf("expected positive LLR for winning score, got %.2f", llr)
+	}
+}
+
+// The test gives up without decision once it reaches the games limit.
+func TestSprtLimit(t *testing.T) {
+	NewEngine(`depth`, 1)
+	epd, _ := NewEpd(initialFEN)
+	m := &Match{ openings: []*Epd{ epd } }
+	for i := range m.engines {
+		m.engines[i], m.engines[i].quiet = engine, true
+		m.caches[i] = *NewCache(1)
+	}
+
+	m.Sprt(NewSprt(-500, 500, 0.05, 0.05).Limit(4))
+	if games := m.wins + m.draws + m.losses; games != 4 {
+		t.Errorf("expected 4 games, got %d", games)
+	}
+}
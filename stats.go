@@ -0,0 +1,52 @@
+package kingside
+
+// Search statistics collected while thinking and reported in UCI "info" lines.
+type Stats struct {
+	nodes     int     // Number of main search nodes.
+	qnodes    int     // Number of quiescence search nodes.
+	seldepth  int     // Selective search depth, i.e. the deepest ply reached.
+}
+
+// Use single statically allocated variable.
+var stats Stats
+
+func (s *Stats) reset() *Stats {
+	*s = Stats{}
+	return s
+}
+
+// Counts main search node.
+func (s *Stats) node() *Stats {
+	s.nodes++
+	return s.visit()
+}
+
+// Counts quiescence search node.
+func (s *Stats) qnode() *Stats {
+	s.qnodes++
+	return s.visit()
+}
+
+// Returns total number of nodes searched.
+func (s *Stats) total() int {
+	return s.nodes + s.qnodes
+}
+
+// Returns number of nodes searched per second given elapsed time in milliseconds.
+func (s *Stats) nps(duration int64) int64 {
+	if duration <= 0 {
+		return 0
+	}
+	return int64(s.total()) * 1000 / duration
+}
+
+// Updates selective depth and halts the search when it exceeds the node limit.
+func (s *Stats) visit() *Stats {
+	if ply := ply(); ply > s.seldepth {
+		s.seldepth = ply
+	}
+	if engine.options.maxNodes > 0 && s.total() >= engine.options.maxNodes {
+		engine.clock.stop()
+	}
+	return s
+}